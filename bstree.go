@@ -6,30 +6,33 @@
 package bsegtree
 
 import (
+	"time"
+
 	"github.com/templexxx/bsegtree/internal/bitmap"
 )
 
+// BSTree is the segment tree of abbreviated keys.
 type BSTree struct {
-	count int // Number of intervals
-	root  *node
-	// interval stack
-	base []Interval
-	// Min value of all intervals
-	min uint64
-	// Max value of all intervals
-	max uint64
-
-	// sum of To - from in intervals.
-	totalDeltas uint64
-
-	// (max - min) / totalDeltas
-	disjointPoint float64
-}
+	// Built tree.
+	segments[uint64]
+
+	// Interval stack.
+	stack
+
+	// From & To of intervals in stack (structure-of-arrays) for scan, made by Build.
+	froms []uint64
+	tos   []uint64
+
+	// policy picks serial or tree for Query.
+	policy Policy
 
-func (t *BSTree) GetAll() []Interval {
-	return t.base
+	// observer is notified of Query, Build & Clear if it's not nil.
+	observer Observer
 }
 
+// bitmaps are for dedup in Query.
+var bitmaps bitmap.Pool
+
 // Relations of two intervals
 const (
 	SUBSET = iota
@@ -40,66 +43,62 @@ const (
 // New creates a Tree with segment tree implementation.
 func New() Tree {
 	t := new(BSTree)
+	t.policy = DefaultPolicy
 	t.Clear()
 	return t
 }
 
-// Push new interval [from, To] To stack
-// This new interval will be added after Build.
-func (t *BSTree) Push(from, to []byte) {
-
-	fa := AbbreviatedKey(from)
-	ta := AbbreviatedKey(to)
-
-	t.base = append(t.base, Interval{t.count, fa, ta})
-	t.count++
-
-	if ta > t.max {
-		t.max = ta
-	}
-	if fa < t.min {
-		t.min = fa
-	}
-
-	t.totalDeltas += ta - fa
-
-	if t.totalDeltas != 0 && t.max-t.min != 0 {
-		t.disjointPoint = float64(t.max-t.min) / float64(t.totalDeltas)
-	}
-}
-
-// PushArray push new intervals [from, To] To stack.
-// These new intervals will be added after Build.
-func (t *BSTree) PushArray(from, to [][]byte) {
-	for i := 0; i < len(from); i++ {
-		t.Push(from[i], to[i])
-	}
-}
-
 // Build builds segment tree out of interval stack
 func (t *BSTree) Build() {
+	t.build(1)
+}
+
+// build builds segment tree with intervals inserted by workers goroutines.
+func (t *BSTree) build(workers int) {
 
 	if len(t.base) == 0 {
 		panic("No intervals in stack To build tree. Push intervals first")
 	}
+	if t.observer != nil {
+		defer t.observeBuild(time.Now())
+	}
+	t.makeColumns()
 	var endpoint []uint64
 	endpoint, t.min, t.max = Endpoints(t.base)
-	leaves := elementaryIntervals(endpoint)
-	// Create tree nodes from interval endpoints
-	t.root = t.insertNodes(leaves)
-	for i := range t.base {
-		t.root.insertInterval(t.base[i])
+	if workers > 1 {
+		done := make(chan struct{})
+		go func() {
+			t.byFrom = sortedByFrom(t.base)
+			t.byTo = sortedByTo(t.base)
+			close(done)
+		}()
+		t.buildNodes(t.base, endpoint, workers)
+		<-done
+	} else {
+		t.buildNodes(t.base, endpoint, 1)
+		t.byFrom = sortedByFrom(t.base)
+		t.byTo = sortedByTo(t.base)
+	}
+	t.buildHistograms()
+
+	if t.policy.Calibrate {
+		t.Calibrate()
 	}
 }
 
 // Query interval, return interval id.
 func (t *BSTree) Query(from, to []byte) []int {
+	return t.QueryUint64(AbbreviatedKey(from), AbbreviatedKey(to))
+}
+
+// QueryUint64 queries interval [fa, ta] of abbreviated keys, return interval id.
+func (t *BSTree) QueryUint64(fa, ta uint64) []int {
 
-	if t.root == nil {
+	if len(t.nodes) == 0 {
 		return nil
 	}
 
-	fa, ta := AbbreviatedKey(from), AbbreviatedKey(to)
+	fa, ta = orderedRange(fa, ta)
 
 	if ta > t.max {
 		ta = t.max
@@ -109,30 +108,57 @@ func (t *BSTree) Query(from, to []byte) []int {
 	}
 
 	cnt := t.estimateIntervals(fa, ta)
+	serial := t.policy.scan(t.count, cnt)
 
-	if (cnt >= 48 && t.count <= 1024) || t.count <= 48 { // If true, serial will be faster.
-		result := make([]int, 0, cnt)
-		for _, i := range t.base {
-			if !i.Disjoint(fa, ta) {
-				result = append(result, i.ID)
-			}
-		}
-		return result
+	if t.observer != nil {
+		return t.observeQuery(fa, ta, cnt, serial)
+	}
+	if serial {
+		return t.queryScan(fa, ta, cnt)
+	}
+	return t.queryTree(fa, ta, cnt)
+}
+
+// queryScan queries [from, to] by scanning columns of the interval stack,
+// cnt is the estimated count of result.
+// scan returns positions in stack, they're mapped to interval IDs.
+func (t *BSTree) queryScan(from, to uint64, cnt int) []int {
+	result := scan(t.froms, t.tos, from, to, make([]int, 0, cnt))
+	for i, pos := range result {
+		result[i] = t.base[pos].ID
+	}
+	return result
+}
+
+// makeColumns makes froms & tos out of interval stack,
+// it's called by Build, Push doesn't touch them.
+func (t *BSTree) makeColumns() {
+	t.froms = make([]uint64, len(t.base))
+	t.tos = make([]uint64, len(t.base))
+	for i, iv := range t.base {
+		t.froms[i], t.tos[i] = iv.From, iv.To
 	}
+}
+
+// queryTree queries [from, to] by traversing tree,
+// cnt is the estimated count of result.
+func (t *BSTree) queryTree(from, to uint64, cnt int) []int {
 
 	result := make([]int, 0, cnt)
 
-	var bm bitmap.Bitmap
+	var bm *bitmap.Bitmap
 	if cnt != 1 { // There is no need to check repeated result when there will be only 1 interval.
-		bm = bitmap.New(t.count)
+		bm = bitmaps.Get(t.count)
 	}
 
-	var bmp *bitmap.Bitmap
-	if bm != nil {
-		bmp = &bm
+	if !t.nodes[0].Disjoint(from, to) {
+		t.querySingle(0, from, to, &result, bm)
 	}
 
-	querySingle(t.root, fa, ta, &result, bmp)
+	if bm != nil {
+		bm.ClearBits(result)
+		bitmaps.Put(bm)
+	}
 
 	if cnt == 1 {
 		if len(result) <= 1 {
@@ -143,127 +169,55 @@ func (t *BSTree) Query(from, to []byte) []int {
 		if (len(result) == 2 && result[0] != result[1]) || (len(result) == 3 && result[0] != result[1] && result[0] != result[2] && result[1] != result[2]) {
 			return result
 		}
-		bm = bitmap.New(t.count)
+		bm = bitmaps.Get(t.count)
 		for _, id := range result {
 			bm.Set(id, true)
 		}
-		result = result[:0]
-		for i := 0; i < t.count; i++ {
-			if bm.Get(i) {
-				result = append(result, i)
-			}
-		}
+		result = bm.AppendSet(result[:0])
+		bm.ClearBits(result)
+		bitmaps.Put(bm)
 	}
 
 	return result
 }
 
-// querySingle traverse tree in search of overlaps
-func querySingle(node *node, from, to uint64, result *[]int, bm *bitmap.Bitmap) {
-
-	if !node.Disjoint(from, to) {
-
-		for _, i := range node.overlap {
-			if bm != nil {
-				if !bm.Get(i.ID) {
-					*result = append(*result, i.ID)
-					bm.Set(i.ID, true)
-				}
-			} else {
-				*result = append(*result, i.ID)
-			}
-		}
-		if node.right != nil {
-			querySingle(node.right, from, to, result, bm)
-		}
-		if node.left != nil {
-			querySingle(node.left, from, to, result, bm)
-		}
-	}
-}
-
 func (t *BSTree) QueryPoint(p []byte) []int {
-
-	return t.Query(p, p)
+	return t.QueryPointUint64(AbbreviatedKey(p))
 }
 
-// Clear reset Tree.
-func (t *BSTree) Clear() {
-	t.count = 0
-	t.root = nil
-	t.base = t.base[:0]
-
-	t.min = 0
-	t.max = 0
-
-	t.totalDeltas = 0
-	t.disjointPoint = 0
+// QueryPointUint64 queries a point of abbreviated key, return interval id.
+func (t *BSTree) QueryPointUint64(p uint64) []int {
+	return t.QueryUint64(p, p)
 }
 
-func (t *BSTree) Clone() Tree {
-
-	nt := &BSTree{
-		count:         t.count,
-		root:          nil,
-		base:          make([]Interval, 0, 1024),
-		min:           t.min,
-		max:           t.max,
-		totalDeltas:   t.totalDeltas,
-		disjointPoint: t.disjointPoint,
-	}
-
-	for _, i := range t.base {
-		nt.base = append(nt.base, Interval{
-			ID:   i.ID,
-			From: i.From,
-			To:   i.To,
-		})
-	}
-	return nt
+// QueryInt64 queries interval [from, to] of int64, return interval id.
+func (t *BSTree) QueryInt64(from, to int64) []int {
+	return t.QueryUint64(int64Key(from), int64Key(to))
 }
 
-// insertNodes builds tree structure from given endpoints
-func (t *BSTree) insertNodes(ls [][2]uint64) *node {
-	var n *node
-	if len(ls) == 1 {
-		n = &node{from: ls[0][0], to: ls[0][1]}
-		n.left = nil
-		n.right = nil
-	} else {
-		n = &node{from: ls[0][0], to: ls[len(ls)-1][1]}
-
-		center := len(ls) / 2
-		n.left = t.insertNodes(ls[:center])
-		n.right = t.insertNodes(ls[center:])
-	}
-	return n
+// QueryPointInt64 queries a point of int64, return interval id.
+func (t *BSTree) QueryPointInt64(p int64) []int {
+	return t.QueryPointUint64(int64Key(p))
 }
 
-// estimateIntervals estimates possible intervals count will be returned by Query/QueryPoint.
-// We assume the dealt of each interval is smooth. I hope so :D
-func (t *BSTree) estimateIntervals(from, to uint64) int {
+// Clear reset Tree.
+func (t *BSTree) Clear() {
+	t.stack.clear()
+	t.nodes = nil
+	t.pool = nil
+	t.froms = t.froms[:0]
+	t.tos = t.tos[:0]
 
-	if t.max == t.min {
-		return 1
+	if t.observer != nil {
+		t.observer.ObserveClear()
 	}
+}
 
-	delta := float64(to - from)
-
-	var cnt int
-	if delta == 0 && t.disjointPoint != 0 {
-
-		cnt = int(round(1/t.disjointPoint, 0))
-
-	} else {
-		cnt = int((delta*float64(t.count))/float64(t.max-t.min)) + 1 // +1 for potential cross intervals and point query.
-	}
+func (t *BSTree) Clone() Tree {
 
-	if cnt < 1 {
-		return 1
+	return &BSTree{
+		stack:    t.stack.clone(),
+		policy:   t.policy,
+		observer: t.observer,
 	}
-	if cnt > t.count {
-		return t.count
-	}
-	return cnt
-
 }
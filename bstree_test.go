@@ -23,7 +23,7 @@ func TestDedup(t *testing.T) {
 	for i := range exp {
 		exp[i] = s[i]
 	}
-	sort.Sort(endpoints(exp))
+	sort.Sort(endpoints[uint64](exp))
 
 	// double s
 	for i := 1024; i < 2048; i++ {
@@ -79,11 +79,15 @@ func TestTreeEstimateIntervals(t *testing.T) {
 // Test segment tree result with serial query:
 // both of [from, To] for every interval is 8 bytes.
 func TestTreeEqualSerialSameLenInterval(t *testing.T) {
+	testEqualSerialSameLenInterval(t, New)
+}
+
+func testEqualSerialSameLenInterval(t *testing.T, newTree func() Tree) {
 
 	rand.Seed(time.Now().UnixNano())
 
 	for j := 0; j < 16; j++ {
-		tree := New()
+		tree := newTree()
 		serial := NewSerial()
 
 		from, to := make([]byte, 8), make([]byte, 8)
@@ -141,11 +145,15 @@ func TestTreeEqualSerialSameLenInterval(t *testing.T) {
 // Test segment tree result with serial query:
 // [from, To] for every interval is 1-10 bytes.
 func TestTreeEqualSerialInterval(t *testing.T) {
+	testEqualSerialInterval(t, New)
+}
+
+func testEqualSerialInterval(t *testing.T, newTree func() Tree) {
 
 	rand.Seed(time.Now().UnixNano())
 
 	for j := 0; j < 16; j++ {
-		tree := New()
+		tree := newTree()
 		serial := NewSerial()
 
 		from, to := make([]byte, 10), make([]byte, 10)
@@ -178,7 +186,7 @@ func TestTreeEqualSerialInterval(t *testing.T) {
 				hasMin = true
 				minN = len(from)
 			} else {
-				if bytes.Compare(min, from) == 1 {
+				if bytes.Compare(min[:minN], from) == 1 {
 					copy(min, from)
 					minN = len(from)
 				}
@@ -188,7 +196,7 @@ func TestTreeEqualSerialInterval(t *testing.T) {
 				hasMax = true
 				maxN = len(to)
 			} else {
-				if bytes.Compare(max, to) == -1 {
+				if bytes.Compare(max[:maxN], to) == -1 {
 					copy(max, to)
 					maxN = len(to)
 				}
@@ -317,6 +325,25 @@ func TestMinimalTree2(t *testing.T) {
 	}
 }
 
+// Inverted ranges are swapped in Push & Query of all implementations.
+func TestInvertedRange(t *testing.T) {
+	for _, newTree := range []func() Tree{New, NewSerial, NewIntervalTree, NewNCList, NewSortedSerial} {
+		tree := newTree()
+		tree.Push([]byte("7"), []byte("3"))
+		tree.Push([]byte("8"), []byte("9"))
+		tree.Build()
+		if result := tree.Query([]byte("5"), []byte("4")); len(result) != 1 || result[0] != 0 {
+			t.Fatalf("fail query inverted range (5, 4): %v", result)
+		}
+		if result := tree.Query([]byte("9"), []byte("1")); len(result) != 2 {
+			t.Fatalf("fail query inverted range (9, 1): %v", result)
+		}
+		if result := tree.QueryPoint([]byte("3")); len(result) != 1 {
+			t.Fatalf("fail query point of inverted interval: %v", result)
+		}
+	}
+}
+
 func TestNormalTree(t *testing.T) {
 	tree := New()
 	tree.Push([]byte("1"), []byte("1"))
@@ -541,4 +568,31 @@ func benchmarkQueryPointCapacity(b *testing.B, t Tree, point []byte, c int) {
 	for i := 0; i < b.N; i++ {
 		_ = t.QueryPoint(point)
 	}
-}
\ No newline at end of file
+}
+
+// Point query on a tree which is much bigger than CPU cache.
+func BenchmarkQueryPointBigTree(b *testing.B) {
+
+	r := rand.New(rand.NewSource(1))
+	t := New()
+	from, to := make([]byte, 8), make([]byte, 8)
+	for i := 0; i < 200000; i++ {
+		f := r.Int63n(1 << 40)
+		binary.BigEndian.PutUint64(from, uint64(f))
+		binary.BigEndian.PutUint64(to, uint64(f+r.Int63n(1<<22)))
+		t.Push(from, to)
+	}
+	t.Build()
+
+	points := make([][]byte, 4096)
+	for i := range points {
+		points[i] = make([]byte, 8)
+		binary.BigEndian.PutUint64(points[i], uint64(r.Int63n(1<<40)))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = t.QueryPoint(points[i%len(points)])
+	}
+}
@@ -0,0 +1,113 @@
+package bsegtree
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUnsorted is returned when intervals aren't sorted for BuildSorted/BulkLoad/BuildFrom.
+var ErrUnsorted = errors.New("bsegtree: intervals are not sorted")
+
+// ErrEmpty is returned when there is no interval for BuildSorted/BulkLoad/BuildFrom.
+var ErrEmpty = errors.New("bsegtree: no intervals to build tree")
+
+// BuildSorted builds segment tree out of interval stack like Build,
+// but intervals must have been pushed in sorted order:
+// both of From & To are non-decreasing (e.g. files of a LSM level).
+// It merges endpoints without sorting, returns ErrUnsorted (without building)
+// if the intervals aren't sorted, or ErrEmpty if there is no interval.
+func (t *BSTree) BuildSorted() error {
+
+	if len(t.base) == 0 {
+		return ErrEmpty
+	}
+	for i := 1; i < len(t.base); i++ {
+		if t.base[i].From < t.base[i-1].From || t.base[i].To < t.base[i-1].To {
+			return ErrUnsorted
+		}
+	}
+	t.buildSorted()
+	return nil
+}
+
+// BulkLoad pushes sorted intervals [from, to] to stack then builds tree with BuildSorted.
+func (t *BSTree) BulkLoad(from, to [][]byte) error {
+	t.PushArray(from, to)
+	return t.BuildSorted()
+}
+
+// IntervalSource provides intervals for BuildFrom one by one.
+type IntervalSource interface {
+	// Next returns the next interval [from, to], ok is false if there is no more.
+	Next() (from, to []byte, ok bool)
+}
+
+// BuildFrom pushes all intervals from src to stack and builds tree like BuildSorted,
+// intervals are checked while being consumed, it returns ErrUnsorted
+// (and intervals pushed so far are left in stack) once there is one out of order,
+// or ErrEmpty if src has no interval.
+func (t *BSTree) BuildFrom(src IntervalSource) error {
+
+	for {
+		from, to, ok := src.Next()
+		if !ok {
+			break
+		}
+		t.Push(from, to)
+		if n := len(t.base); n > 1 {
+			if t.base[n-1].From < t.base[n-2].From || t.base[n-1].To < t.base[n-2].To {
+				return ErrUnsorted
+			}
+		}
+	}
+	if len(t.base) == 0 {
+		return ErrEmpty
+	}
+	t.buildSorted()
+	return nil
+}
+
+// buildSorted builds tree out of sorted interval stack.
+func (t *BSTree) buildSorted() {
+	if t.observer != nil {
+		defer t.observeBuild(time.Now())
+	}
+	t.makeColumns()
+	endpoint := mergeEndpoints(t.base)
+	t.min, t.max = endpoint[0], endpoint[len(endpoint)-1]
+	t.buildNodes(t.base, endpoint, 1)
+
+	// Sorted by From & To already, ties are broken by ID (push order).
+	// base is copied (changing stack directly won't break byFrom & byTo),
+	// byFrom & byTo share the copy, both are read-only.
+	t.byFrom = append(make([]Interval, 0, len(t.base)), t.base...)
+	t.byTo = t.byFrom
+	t.buildHistograms()
+
+	if t.policy.Calibrate {
+		t.Calibrate()
+	}
+}
+
+// mergeEndpoints returns all endpoints (sorted, unique) of sorted intervals
+// by merging From & To.
+func mergeEndpoints(base []Interval) []uint64 {
+
+	n := len(base)
+	result := make([]uint64, 0, n*2)
+	i, j := 0, 0
+	for i < n || j < n {
+		var p uint64
+		if j == n || (i < n && base[i].From <= base[j].To) {
+			p = base[i].From
+			i++
+		} else {
+			p = base[j].To
+			j++
+		}
+		if len(result) == 0 || result[len(result)-1] != p {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,76 @@
+package bsegtree
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// sliceSource is an IntervalSource on slices.
+type sliceSource struct {
+	from, to [][]byte
+}
+
+func (s *sliceSource) Next() (from, to []byte, ok bool) {
+	if len(s.from) == 0 {
+		return nil, nil, false
+	}
+	from, to = s.from[0], s.to[0]
+	s.from, s.to = s.from[1:], s.to[1:]
+	return from, to, true
+}
+
+func TestBuildSorted(t *testing.T) {
+
+	rand.Seed(time.Now().UnixNano())
+
+	froms, tos := make([][]byte, 1024), make([][]byte, 1024)
+	var fn, tn uint64
+	for i := range froms {
+		fn += uint64(rand.Int63n(100)) + 1
+		if tn < fn {
+			tn = fn
+		}
+		tn += uint64(rand.Int63n(200))
+		froms[i], tos[i] = make([]byte, 8), make([]byte, 8)
+		binary.BigEndian.PutUint64(froms[i], fn)
+		binary.BigEndian.PutUint64(tos[i], tn)
+	}
+
+	exp := New()
+	exp.PushArray(froms, tos)
+	exp.Build()
+
+	act := New()
+	if err := act.(*BSTree).BulkLoad(froms, tos); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(exp, act) {
+		t.Fatal("BulkLoad mismatched with Build")
+	}
+
+	act = New()
+	if err := act.(*BSTree).BuildFrom(&sliceSource{from: froms, to: tos}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(exp, act) {
+		t.Fatal("BuildFrom mismatched with Build")
+	}
+
+	froms[10], froms[11] = froms[11], froms[10]
+	if err := New().(*BSTree).BulkLoad(froms, tos); err != ErrUnsorted {
+		t.Fatalf("should be unsorted, got: %v", err)
+	}
+	if err := New().(*BSTree).BuildFrom(&sliceSource{from: froms, to: tos}); err != ErrUnsorted {
+		t.Fatalf("should be unsorted, got: %v", err)
+	}
+
+	if err := New().(*BSTree).BuildFrom(&sliceSource{}); err != ErrEmpty {
+		t.Fatalf("should be empty, got: %v", err)
+	}
+	if err := New().(*BSTree).BulkLoad(nil, nil); err != ErrEmpty {
+		t.Fatalf("should be empty, got: %v", err)
+	}
+}
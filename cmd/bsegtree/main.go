@@ -0,0 +1,297 @@
+// Command bsegtree builds segment trees of bytes intervals offline,
+// then queries & inspects them.
+//
+// Usage:
+//
+//	bsegtree build [-format csv|tsv|jsonl] [-hex] [-workers n] [-in file] -o tree
+//	bsegtree query [-hex] -tree tree from to
+//	bsegtree point [-hex] -tree tree key
+//	bsegtree stats -tree tree
+//	bsegtree dump [-format text|dot] -tree tree
+//
+// Intervals are read one per record: from & to.
+// In JSON lines, a record is {"from": "...", "to": "..."}.
+// Keys are raw strings, or hex encoded with -hex.
+// ID of an interval is its record number (starting from 0), queries print IDs one per line.
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/templexxx/bsegtree"
+)
+
+const usage = `usage:
+	bsegtree build [-format csv|tsv|jsonl] [-hex] [-workers n] [-in file] -o tree
+	bsegtree query [-hex] -tree tree from to
+	bsegtree point [-hex] -tree tree key
+	bsegtree stats -tree tree
+	bsegtree dump [-format text|dot] -tree tree`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "bsegtree:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	cmd, args := args[0], args[1:]
+
+	// Flags are registered by the subcommands using them,
+	// e.g. build doesn't take -tree.
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	treeFlag := func() *string { return fs.String("tree", "", "serialized tree made by build") }
+	hexFlag := func() *bool { return fs.Bool("hex", false, "keys are hex encoded") }
+
+	switch cmd {
+	case "build":
+		hexKeys := hexFlag()
+		format := fs.String("format", "csv", "input format: csv, tsv or jsonl")
+		in := fs.String("in", "-", "input file, - is stdin")
+		out := fs.String("o", "", "output file of tree, - is stdout")
+		workers := fs.Int("workers", 1, "goroutines for building, <= 0 means GOMAXPROCS")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if *out == "" {
+			return errors.New("build: -o is required")
+		}
+		return build(stdin, stdout, *in, *out, *format, *hexKeys, *workers)
+
+	case "query", "point":
+		treePath, hexKeys := treeFlag(), hexFlag()
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		n := 2
+		if cmd == "point" {
+			n = 1
+		}
+		if fs.NArg() != n {
+			return errors.New(usage)
+		}
+		keys := make([][]byte, n)
+		for i := range keys {
+			k, err := decodeKey(fs.Arg(i), *hexKeys)
+			if err != nil {
+				return err
+			}
+			keys[i] = k
+		}
+		tree, err := load(*treePath)
+		if err != nil {
+			return err
+		}
+		var result []int
+		if cmd == "point" {
+			result = tree.QueryPoint(keys[0])
+		} else {
+			result = tree.Query(keys[0], keys[1])
+		}
+		sort.Ints(result)
+		w := bufio.NewWriter(stdout)
+		for _, id := range result {
+			fmt.Fprintln(w, id)
+		}
+		return w.Flush()
+
+	case "stats":
+		treePath := treeFlag()
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		tree, err := load(*treePath)
+		if err != nil {
+			return err
+		}
+		s := tree.Stats()
+		_, err = fmt.Fprintf(stdout, `intervals       %d
+nodes           %d
+depth           %d
+leaves          %d
+overlaps        %d
+max overlaps    %d
+duplication     %.2f
+memory (bytes)  %d
+min             %016x
+max             %016x
+disjoint point  %g
+`, s.Intervals, s.Nodes, s.Depth, s.Leaves, s.Overlaps, s.MaxOverlaps, s.Duplication, s.Memory,
+			s.Min, s.Max, s.DisjointPoint)
+		return err
+
+	case "dump":
+		treePath := treeFlag()
+		format := fs.String("format", "text", "output format: text or dot (Graphviz)")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		var df bsegtree.DumpFormat
+		switch *format {
+		case "text":
+			df = bsegtree.DumpText
+		case "dot":
+			df = bsegtree.DumpDOT
+		default:
+			return fmt.Errorf("unknown dump format %q", *format)
+		}
+		tree, err := load(*treePath)
+		if err != nil {
+			return err
+		}
+		w := bufio.NewWriter(stdout)
+		if err = tree.Dump(w, df); err != nil {
+			return err
+		}
+		return w.Flush()
+
+	default:
+		return fmt.Errorf("unknown command %q\n%s", cmd, usage)
+	}
+}
+
+// build reads intervals from in, and writes built tree to out.
+func build(stdin io.Reader, stdout io.Writer, in, out, format string, hexKeys bool, workers int) error {
+
+	r := stdin
+	if in != "-" {
+		f, err := os.Open(in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	tree := bsegtree.New().(*bsegtree.BSTree)
+	push := func(from, to string) error {
+		f, err := decodeKey(from, hexKeys)
+		if err != nil {
+			return err
+		}
+		t, err := decodeKey(to, hexKeys)
+		if err != nil {
+			return err
+		}
+		tree.Push(f, t)
+		return nil
+	}
+
+	var err error
+	switch format {
+	case "csv":
+		err = readCSV(r, ',', push)
+	case "tsv":
+		err = readCSV(r, '\t', push)
+	case "jsonl":
+		err = readJSONLines(r, push)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	if len(tree.GetAll()) == 0 {
+		return errors.New("no intervals in input")
+	}
+
+	if workers == 1 {
+		tree.Build()
+	} else {
+		tree.BuildParallel(workers)
+	}
+	data, err := tree.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if out == "-" {
+		_, err = stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(out, data, 0644)
+}
+
+func readCSV(r io.Reader, comma rune, push func(from, to string) error) error {
+
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = 2
+	cr.ReuseRecord = true
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = push(rec[0], rec[1]); err != nil {
+			return err
+		}
+	}
+}
+
+func readJSONLines(r io.Reader, push func(from, to string) error) error {
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for s.Scan() {
+		line++
+		if len(s.Bytes()) == 0 {
+			continue
+		}
+		var rec struct {
+			From *string `json:"from"`
+			To   *string `json:"to"`
+		}
+		if err := json.Unmarshal(s.Bytes(), &rec); err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		if rec.From == nil || rec.To == nil {
+			return fmt.Errorf("line %d: from & to are required", line)
+		}
+		if err := push(*rec.From, *rec.To); err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+	return s.Err()
+}
+
+func decodeKey(s string, hexKeys bool) ([]byte, error) {
+	if !hexKeys {
+		return []byte(s), nil
+	}
+	return hex.DecodeString(s)
+}
+
+// load reads tree made by build.
+func load(path string) (*bsegtree.BSTree, error) {
+
+	if path == "" {
+		return nil, errors.New("-tree is required")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tree := bsegtree.New().(*bsegtree.BSTree)
+	if err = tree.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return tree, nil
+}
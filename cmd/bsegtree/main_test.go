@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runOut(t *testing.T, stdin string, args ...string) string {
+	t.Helper()
+
+	var out bytes.Buffer
+	if err := run(args, strings.NewReader(stdin), &out); err != nil {
+		t.Fatalf("%v: %v", args, err)
+	}
+	return out.String()
+}
+
+func TestBuildQuery(t *testing.T) {
+
+	dir := t.TempDir()
+	inputs := []struct {
+		format, hex, data string
+	}{
+		{"csv", "false", "a,c\nb,d\nx,z\n"},
+		{"tsv", "false", "a\tc\nb\td\nx\tz\n"},
+		{"jsonl", "false", `{"from": "a", "to": "c"}` + "\n\n" + `{"from": "b", "to": "d"}` + "\n" + `{"from": "x", "to": "z"}` + "\n"},
+		{"csv", "true", "61,63\n62,64\n78,7a\n"},
+	}
+	for _, in := range inputs {
+		path := filepath.Join(dir, in.format+in.hex)
+		runOut(t, in.data, "build", "-format", in.format, "-hex="+in.hex, "-o", path)
+
+		from, to, p := "b", "c", "y"
+		if in.hex == "true" {
+			from, to, p = "62", "63", "79"
+		}
+		if act := runOut(t, "", "query", "-hex="+in.hex, "-tree", path, from, to); act != "0\n1\n" {
+			t.Fatalf("wrong query result of %s: %q", path, act)
+		}
+		if act := runOut(t, "", "point", "-hex="+in.hex, "-tree", path, p); act != "2\n" {
+			t.Fatalf("wrong point result of %s: %q", path, act)
+		}
+	}
+
+	path := filepath.Join(dir, "csvfalse")
+	if act := runOut(t, "", "stats", "-tree", path); !strings.HasPrefix(act, "intervals       3\n") {
+		t.Fatalf("wrong stats: %q", act)
+	}
+	if act := runOut(t, "", "dump", "-tree", path); !strings.HasPrefix(act, `[6100000000000000 "a", 7a00000000000000 "z"]`) {
+		t.Fatalf("wrong dump: %q", act)
+	}
+	if act := runOut(t, "", "dump", "-format", "dot", "-tree", path); !strings.HasPrefix(act, "digraph bsegtree {") {
+		t.Fatalf("wrong DOT dump: %q", act)
+	}
+}
+
+func TestBadInput(t *testing.T) {
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tree")
+	valid := filepath.Join(dir, "valid")
+	runOut(t, "a,b\n", "build", "-o", valid)
+	for _, c := range []struct {
+		stdin string
+		args  []string
+	}{
+		{"", nil},
+		{"", []string{"unknown"}},
+		{"a,b\n", []string{"build"}},
+		{"", []string{"build", "-o", path}},
+		{"a,b,c\n", []string{"build", "-o", path}},
+		{"zz,01\n", []string{"build", "-hex", "-o", path}},
+		{`{"from": "a"}`, []string{"build", "-format", "jsonl", "-o", path}},
+		{"", []string{"query", "-tree", path, "a"}},
+		{"a,b\n", []string{"build", "-tree", path, "-o", path}},
+		{"", []string{"stats", "-hex", "-tree", valid}},
+		{"", []string{"dump", "-hex", "-tree", valid}},
+		{"", []string{"dump", "-format", "svg", "-tree", path}},
+		{"", []string{"point", "-tree", filepath.Join(dir, "none"), "a"}},
+	} {
+		if err := run(c.args, strings.NewReader(c.stdin), new(bytes.Buffer)); err == nil {
+			t.Fatalf("%v should fail", c.args)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package bsegtree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DumpFormat is the output format of Dump.
+type DumpFormat int
+
+const (
+	// DumpText is indented text, a node per line, left child is before right one.
+	DumpText DumpFormat = iota
+	// DumpDOT is Graphviz DOT, render it by: dot -Tsvg.
+	DumpDOT
+)
+
+// Dump writes built tree to w in format.
+// Each node shows [from, to] of abbreviated keys in hex & printable (quoted, without padding zeros),
+// and IDs of its overlap intervals.
+func (t *BSTree) Dump(w io.Writer, format DumpFormat) error {
+
+	if len(t.nodes) == 0 {
+		return nil
+	}
+	switch format {
+	case DumpText:
+		return t.dumpText(w, 0, 0)
+	case DumpDOT:
+		if _, err := io.WriteString(w, "digraph bsegtree {\n\tnode [shape=box, fontname=monospace];\n"); err != nil {
+			return err
+		}
+		if err := t.dumpDOT(w, 0); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "}\n")
+		return err
+	default:
+		return fmt.Errorf("bsegtree: unknown dump format %d", format)
+	}
+}
+
+func (t *BSTree) dumpText(w io.Writer, n int32, depth int) error {
+
+	nd := &t.nodes[n]
+	if _, err := fmt.Fprintf(w, "%*s%s %v\n", depth*2, "", nodeRange(nd), t.pool[nd.lo:nd.hi]); err != nil {
+		return err
+	}
+	if nd.left != 0 {
+		if err := t.dumpText(w, nd.left, depth+1); err != nil {
+			return err
+		}
+		return t.dumpText(w, nd.right, depth+1)
+	}
+	return nil
+}
+
+// dotEscaper escapes label in DOT.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func (t *BSTree) dumpDOT(w io.Writer, n int32) error {
+
+	nd := &t.nodes[n]
+	label := dotEscaper.Replace(nodeRange(nd))
+	if nd.lo != nd.hi {
+		label += `\n` + fmt.Sprint(t.pool[nd.lo:nd.hi])
+	}
+	if _, err := fmt.Fprintf(w, "\tn%d [label=\"%s\"];\n", n, label); err != nil {
+		return err
+	}
+	if nd.left != 0 {
+		if _, err := fmt.Fprintf(w, "\tn%d -> n%d;\n\tn%d -> n%d;\n", n, nd.left, n, nd.right); err != nil {
+			return err
+		}
+		if err := t.dumpDOT(w, nd.left); err != nil {
+			return err
+		}
+		return t.dumpDOT(w, nd.right)
+	}
+	return nil
+}
+
+// nodeRange returns [from, to] of node in hex & printable key.
+func nodeRange(nd *node[uint64]) string {
+	return fmt.Sprintf("[%016x %s, %016x %s]", nd.from, printableKey(nd.from), nd.to, printableKey(nd.to))
+}
+
+// printableKey returns quoted abbreviated key k without padding zeros.
+func printableKey(k uint64) string {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], k)
+	return strconv.QuoteToASCII(string(bytes.TrimRight(b[:], "\x00")))
+}
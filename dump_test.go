@@ -0,0 +1,56 @@
+package bsegtree
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+
+	tree := New().(*BSTree)
+	var buf bytes.Buffer
+	if err := tree.Dump(&buf, DumpText); err != nil || buf.Len() != 0 {
+		t.Fatal("should dump nothing before Build")
+	}
+
+	tree.Push([]byte("a"), []byte("b"))
+	tree.Push([]byte("b"), []byte("c\x01\""))
+	tree.Build()
+
+	if err := tree.Dump(&buf, DumpText); err != nil {
+		t.Fatal(err)
+	}
+	exp := `[6100000000000000 "a", 6301220000000000 "c\x01\""] []
+  [6100000000000000 "a", 6200000000000000 "b"] [0]
+    [6100000000000000 "a", 6100000000000000 "a"] []
+    [6100000000000000 "a", 6200000000000000 "b"] []
+  [6200000000000000 "b", 6301220000000000 "c\x01\""] [1]
+    [6200000000000000 "b", 6200000000000000 "b"] [0]
+    [6200000000000000 "b", 6301220000000000 "c\x01\""] []
+      [6200000000000000 "b", 6301220000000000 "c\x01\""] []
+      [6301220000000000 "c\x01\"", 6301220000000000 "c\x01\""] []
+`
+	if buf.String() != exp {
+		t.Fatalf("wrong text dump:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := tree.Dump(&buf, DumpDOT); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+	for _, s := range []string{
+		"digraph bsegtree {\n",
+		`n0 [label="[6100000000000000 \"a\", 6301220000000000 \"c\\x01\\\"\"]"];`,
+		`[label="[6100000000000000 \"a\", 6200000000000000 \"b\"]\n[0]"];`,
+		"\tn0 -> n",
+	} {
+		if !bytes.Contains(buf.Bytes(), []byte(s)) {
+			t.Fatalf("wrong DOT dump, %q is missing:\n%s", s, dot)
+		}
+	}
+
+	if tree.Dump(&buf, DumpFormat(-1)) == nil {
+		t.Fatal("unknown format should fail")
+	}
+}
@@ -0,0 +1,179 @@
+// Package encoding encodes values to bytes in the same order as the values,
+// so they can be keys of bsegtree:
+//
+//	a < b  iff  bytes.Compare(Encode(a), Encode(b)) < 0
+//
+// Numbers are fixed length, strings are escaped & terminated,
+// so any of them could be appended one by one as a tuple,
+// which is ordered by the first element, then the second and so on.
+//
+// bsegtree only compares the first 8 bytes of keys (see bsegtree.AbbreviatedKey),
+// longer keys (e.g. tuple of two int64) are compared by their 8 bytes prefix,
+// so query may return intervals which end/start in the same prefix out of the range.
+package encoding
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+)
+
+// ErrShort is returned when there are not enough bytes to decode.
+var ErrShort = errors.New("encoding: short buffer")
+
+// ErrUnterminated is returned when an escaped string has no terminator.
+var ErrUnterminated = errors.New("encoding: unterminated string")
+
+const signBit = 1 << 63
+
+// AppendUint64 appends v in big endian.
+func AppendUint64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+// DecodeUint64 decodes uint64 encoded by AppendUint64, returns the rest bytes.
+func DecodeUint64(b []byte) (uint64, []byte, error) {
+	if len(b) < 8 {
+		return 0, b, ErrShort
+	}
+	return binary.BigEndian.Uint64(b), b[8:], nil
+}
+
+// AppendUint32 appends v in big endian.
+func AppendUint32(b []byte, v uint32) []byte {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+// DecodeUint32 decodes uint32 encoded by AppendUint32, returns the rest bytes.
+func DecodeUint32(b []byte) (uint32, []byte, error) {
+	if len(b) < 4 {
+		return 0, b, ErrShort
+	}
+	return binary.BigEndian.Uint32(b), b[4:], nil
+}
+
+// AppendInt64 appends v with sign bit flipped in big endian,
+// so negative numbers are before positive ones.
+func AppendInt64(b []byte, v int64) []byte {
+	return AppendUint64(b, uint64(v)^signBit)
+}
+
+// DecodeInt64 decodes int64 encoded by AppendInt64, returns the rest bytes.
+func DecodeInt64(b []byte) (int64, []byte, error) {
+	u, rest, err := DecodeUint64(b)
+	return int64(u ^ signBit), rest, err
+}
+
+// AppendInt32 appends v with sign bit flipped in big endian.
+func AppendInt32(b []byte, v int32) []byte {
+	return AppendUint32(b, uint32(v)^1<<31)
+}
+
+// DecodeInt32 decodes int32 encoded by AppendInt32, returns the rest bytes.
+func DecodeInt32(b []byte) (int32, []byte, error) {
+	u, rest, err := DecodeUint32(b)
+	return int32(u ^ 1<<31), rest, err
+}
+
+// float64Key transforms IEEE 754 bits of f to uint64 in the same order:
+// positive numbers get sign bit set, negative ones get all bits flipped.
+// -0 is 0, NaNs are after +Inf.
+func float64Key(f float64) uint64 {
+	if f == 0 {
+		f = 0 // -0.
+	}
+	if math.IsNaN(f) {
+		f = math.NaN() // Canonical NaN.
+	}
+	u := math.Float64bits(f)
+	if u&signBit != 0 {
+		return ^u
+	}
+	return u | signBit
+}
+
+// AppendFloat64 appends f in order of value,
+// -0 is encoded as 0 and all NaNs are encoded as the same one after +Inf.
+func AppendFloat64(b []byte, f float64) []byte {
+	return AppendUint64(b, float64Key(f))
+}
+
+// DecodeFloat64 decodes float64 encoded by AppendFloat64, returns the rest bytes.
+func DecodeFloat64(b []byte) (float64, []byte, error) {
+	u, rest, err := DecodeUint64(b)
+	if u&signBit != 0 {
+		u &^= signBit
+	} else {
+		u = ^u
+	}
+	return math.Float64frombits(u), rest, err
+}
+
+// AppendTime appends t as nanoseconds since Unix epoch (by AppendInt64),
+// t must be in years 1678-2262.
+func AppendTime(b []byte, t time.Time) []byte {
+	return AppendInt64(b, t.UnixNano())
+}
+
+// DecodeTime decodes time encoded by AppendTime (in UTC), returns the rest bytes.
+func DecodeTime(b []byte) (time.Time, []byte, error) {
+	n, rest, err := DecodeInt64(b)
+	if err != nil {
+		return time.Time{}, rest, err
+	}
+	return time.Unix(0, n).UTC(), rest, nil
+}
+
+// Escaped string: 0x00 in s is escaped as 0x00 0xff, and it's terminated by 0x00 0x01,
+// so a string is before the ones it's the prefix of, and it's self-delimiting in tuple.
+const (
+	escape     = 0x00
+	escaped00  = 0xff
+	terminator = 0x01
+)
+
+// AppendString appends escaped & terminated s.
+func AppendString(b []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		if s[i] == escape {
+			b = append(b, escape, escaped00)
+		} else {
+			b = append(b, s[i])
+		}
+	}
+	return append(b, escape, terminator)
+}
+
+// AppendBytes appends escaped & terminated s as AppendString.
+func AppendBytes(b []byte, s []byte) []byte {
+	return AppendString(b, string(s))
+}
+
+// DecodeString decodes string encoded by AppendString/AppendBytes, returns the rest bytes.
+func DecodeString(b []byte) (string, []byte, error) {
+	s := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		if b[i] != escape {
+			s = append(s, b[i])
+			continue
+		}
+		if i+1 == len(b) {
+			break
+		}
+		switch b[i+1] {
+		case terminator:
+			return string(s), b[i+2:], nil
+		case escaped00:
+			s = append(s, escape)
+			i++
+		default:
+			return "", b, errors.New("encoding: invalid escape in string")
+		}
+	}
+	return "", b, ErrUnterminated
+}
@@ -0,0 +1,145 @@
+package encoding
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"testing"
+	"time"
+)
+
+// checkOrder checks encoded values are in the same order as values (sorted ascending).
+func checkOrder(t *testing.T, name string, encoded [][]byte) {
+	t.Helper()
+
+	for i := 1; i < len(encoded); i++ {
+		if bytes.Compare(encoded[i-1], encoded[i]) >= 0 {
+			t.Fatalf("%s: wrong order at %d: %x >= %x", name, i, encoded[i-1], encoded[i])
+		}
+	}
+}
+
+func TestInt(t *testing.T) {
+
+	vs := []int64{math.MinInt64, -1 << 40, -2, -1, 0, 1, 2, 1 << 40, math.MaxInt64}
+	var encoded [][]byte
+	for _, v := range vs {
+		b := AppendInt64(nil, v)
+		encoded = append(encoded, b)
+		act, rest, err := DecodeInt64(b)
+		if err != nil || act != v || len(rest) != 0 {
+			t.Fatalf("wrong int64 decoding, exp: %d, got: %d, err: %v", v, act, err)
+		}
+	}
+	checkOrder(t, "int64", encoded)
+
+	encoded = encoded[:0]
+	for _, v := range []int32{math.MinInt32, -1, 0, 1, math.MaxInt32} {
+		b := AppendInt32(nil, v)
+		encoded = append(encoded, b)
+		act, _, err := DecodeInt32(b)
+		if err != nil || act != v {
+			t.Fatalf("wrong int32 decoding, exp: %d, got: %d, err: %v", v, act, err)
+		}
+	}
+	checkOrder(t, "int32", encoded)
+
+	encoded = encoded[:0]
+	for _, v := range []uint64{0, 1, 255, 256, math.MaxUint64} {
+		b := AppendUint64(nil, v)
+		encoded = append(encoded, b)
+		act, _, err := DecodeUint64(b)
+		if err != nil || act != v {
+			t.Fatalf("wrong uint64 decoding, exp: %d, got: %d, err: %v", v, act, err)
+		}
+	}
+	checkOrder(t, "uint64", encoded)
+
+	if _, _, err := DecodeUint64(make([]byte, 7)); err != ErrShort {
+		t.Fatal("short buffer should fail")
+	}
+}
+
+func TestFloat(t *testing.T) {
+
+	vs := []float64{math.Inf(-1), -math.MaxFloat64, -1.5, -math.SmallestNonzeroFloat64, 0,
+		math.SmallestNonzeroFloat64, 1, 1.5, math.MaxFloat64, math.Inf(1), math.NaN()}
+	var encoded [][]byte
+	for _, v := range vs {
+		b := AppendFloat64(nil, v)
+		encoded = append(encoded, b)
+		act, _, err := DecodeFloat64(b)
+		if err != nil || (act != v && !(math.IsNaN(v) && math.IsNaN(act))) {
+			t.Fatalf("wrong float64 decoding, exp: %g, got: %g, err: %v", v, act, err)
+		}
+	}
+	checkOrder(t, "float64", encoded)
+
+	if !bytes.Equal(AppendFloat64(nil, math.Copysign(0, -1)), AppendFloat64(nil, 0)) {
+		t.Fatal("-0 should be encoded as 0")
+	}
+}
+
+func TestTime(t *testing.T) {
+
+	base := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	vs := []time.Time{time.Unix(0, 0).Add(-time.Hour), time.Unix(0, 0), base, base.Add(time.Nanosecond)}
+	var encoded [][]byte
+	for _, v := range vs {
+		b := AppendTime(nil, v)
+		encoded = append(encoded, b)
+		act, _, err := DecodeTime(b)
+		if err != nil || !act.Equal(v) {
+			t.Fatalf("wrong time decoding, exp: %v, got: %v, err: %v", v, act, err)
+		}
+	}
+	checkOrder(t, "time", encoded)
+}
+
+func TestString(t *testing.T) {
+
+	vs := []string{"", "\x00", "\x00\x00", "\x00\x01", "\x01", "a", "a\x00", "a\x00b", "ab", "b", "\xff"}
+	if !sort.StringsAreSorted(vs) {
+		t.Fatal("test strings aren't sorted")
+	}
+	var encoded [][]byte
+	for _, v := range vs {
+		b := AppendString(nil, v)
+		encoded = append(encoded, b)
+		act, rest, err := DecodeString(append(b, 'x'))
+		if err != nil || act != v || string(rest) != "x" {
+			t.Fatalf("wrong string decoding, exp: %q, got: %q, rest: %q, err: %v", v, act, rest, err)
+		}
+	}
+	checkOrder(t, "string", encoded)
+
+	for _, bad := range []string{"a", "a\x00", "a\x00\x02"} {
+		if _, _, err := DecodeString([]byte(bad)); err == nil {
+			t.Fatalf("%q should fail", bad)
+		}
+	}
+}
+
+func TestTuple(t *testing.T) {
+
+	type tuple struct {
+		s string
+		n int64
+	}
+	vs := []tuple{{"", 5}, {"a", -1}, {"a", 0}, {"a\x00", -5}, {"ab", math.MinInt64}, {"b", 0}}
+	var encoded [][]byte
+	for _, v := range vs {
+		b := AppendInt64(AppendString(nil, v.s), v.n)
+		encoded = append(encoded, b)
+
+		s, rest, err := DecodeString(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, rest, err := DecodeInt64(rest)
+		if err != nil || s != v.s || n != v.n || len(rest) != 0 {
+			t.Fatalf("wrong tuple decoding, exp: %v, got: {%q %d}, err: %v", v, s, n, err)
+		}
+	}
+	checkOrder(t, "tuple", encoded)
+}
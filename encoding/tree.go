@@ -0,0 +1,79 @@
+package encoding
+
+import (
+	"time"
+
+	"github.com/templexxx/bsegtree"
+)
+
+// PushUint64 pushes interval [from, to] of uint64 to t.
+func PushUint64(t bsegtree.Tree, from, to uint64) {
+	var f, e [8]byte
+	t.Push(AppendUint64(f[:0], from), AppendUint64(e[:0], to))
+}
+
+// QueryUint64 queries interval [from, to] of uint64 in t.
+func QueryUint64(t bsegtree.Tree, from, to uint64) []int {
+	var f, e [8]byte
+	return t.Query(AppendUint64(f[:0], from), AppendUint64(e[:0], to))
+}
+
+// QueryPointUint64 queries point p of uint64 in t.
+func QueryPointUint64(t bsegtree.Tree, p uint64) []int {
+	var b [8]byte
+	return t.QueryPoint(AppendUint64(b[:0], p))
+}
+
+// PushInt64 pushes interval [from, to] of int64 to t.
+func PushInt64(t bsegtree.Tree, from, to int64) {
+	var f, e [8]byte
+	t.Push(AppendInt64(f[:0], from), AppendInt64(e[:0], to))
+}
+
+// QueryInt64 queries interval [from, to] of int64 in t.
+func QueryInt64(t bsegtree.Tree, from, to int64) []int {
+	var f, e [8]byte
+	return t.Query(AppendInt64(f[:0], from), AppendInt64(e[:0], to))
+}
+
+// QueryPointInt64 queries point p of int64 in t.
+func QueryPointInt64(t bsegtree.Tree, p int64) []int {
+	var b [8]byte
+	return t.QueryPoint(AppendInt64(b[:0], p))
+}
+
+// PushFloat64 pushes interval [from, to] of float64 to t.
+func PushFloat64(t bsegtree.Tree, from, to float64) {
+	var f, e [8]byte
+	t.Push(AppendFloat64(f[:0], from), AppendFloat64(e[:0], to))
+}
+
+// QueryFloat64 queries interval [from, to] of float64 in t.
+func QueryFloat64(t bsegtree.Tree, from, to float64) []int {
+	var f, e [8]byte
+	return t.Query(AppendFloat64(f[:0], from), AppendFloat64(e[:0], to))
+}
+
+// QueryPointFloat64 queries point p of float64 in t.
+func QueryPointFloat64(t bsegtree.Tree, p float64) []int {
+	var b [8]byte
+	return t.QueryPoint(AppendFloat64(b[:0], p))
+}
+
+// PushTime pushes interval [from, to] of time to t.
+func PushTime(t bsegtree.Tree, from, to time.Time) {
+	var f, e [8]byte
+	t.Push(AppendTime(f[:0], from), AppendTime(e[:0], to))
+}
+
+// QueryTime queries interval [from, to] of time in t.
+func QueryTime(t bsegtree.Tree, from, to time.Time) []int {
+	var f, e [8]byte
+	return t.Query(AppendTime(f[:0], from), AppendTime(e[:0], to))
+}
+
+// QueryPointTime queries point p of time in t.
+func QueryPointTime(t bsegtree.Tree, p time.Time) []int {
+	var b [8]byte
+	return t.QueryPoint(AppendTime(b[:0], p))
+}
@@ -0,0 +1,73 @@
+package encoding
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/templexxx/bsegtree"
+)
+
+func sorted(ids []int) []int {
+	sort.Ints(ids)
+	return ids
+}
+
+func TestTreeInt64(t *testing.T) {
+
+	tree := bsegtree.New()
+	PushInt64(tree, -100, -10)                    // 0
+	PushInt64(tree, -20, 20)                      // 1
+	PushInt64(tree, 10, 100)                      // 2
+	PushInt64(tree, math.MinInt64, math.MaxInt64) // 3
+	tree.Build()
+
+	if act := sorted(QueryInt64(tree, -15, -12)); !reflect.DeepEqual(act, []int{0, 1, 3}) {
+		t.Fatalf("wrong result: %v", act)
+	}
+	if act := sorted(QueryPointInt64(tree, 15)); !reflect.DeepEqual(act, []int{1, 2, 3}) {
+		t.Fatalf("wrong result: %v", act)
+	}
+	if act := sorted(QueryInt64(tree, 101, 200)); !reflect.DeepEqual(act, []int{3}) {
+		t.Fatalf("wrong result: %v", act)
+	}
+}
+
+func TestTreeUint64Float64Time(t *testing.T) {
+
+	tree := bsegtree.New()
+	PushUint64(tree, 1, 5)
+	PushUint64(tree, 1<<63, math.MaxUint64)
+	tree.Build()
+	if act := sorted(QueryUint64(tree, 5, 1<<63)); !reflect.DeepEqual(act, []int{0, 1}) {
+		t.Fatalf("wrong uint64 result: %v", act)
+	}
+	if act := QueryPointUint64(tree, 6); len(act) != 0 {
+		t.Fatalf("wrong uint64 result: %v", act)
+	}
+
+	tree = bsegtree.New()
+	PushFloat64(tree, -1.5, -0.5)
+	PushFloat64(tree, -0.25, 2.5)
+	tree.Build()
+	if act := QueryPointFloat64(tree, -1); !reflect.DeepEqual(act, []int{0}) {
+		t.Fatalf("wrong float64 result: %v", act)
+	}
+	if act := sorted(QueryFloat64(tree, -0.5, 0)); !reflect.DeepEqual(act, []int{0, 1}) {
+		t.Fatalf("wrong float64 result: %v", act)
+	}
+
+	tree = bsegtree.New()
+	now := time.Now()
+	PushTime(tree, now.Add(-time.Hour), now)
+	PushTime(tree, now, now.Add(time.Hour))
+	tree.Build()
+	if act := QueryPointTime(tree, now.Add(-time.Minute)); !reflect.DeepEqual(act, []int{0}) {
+		t.Fatalf("wrong time result: %v", act)
+	}
+	if act := sorted(QueryTime(tree, now, now.Add(time.Minute))); !reflect.DeepEqual(act, []int{0, 1}) {
+		t.Fatalf("wrong time result: %v", act)
+	}
+}
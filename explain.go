@@ -0,0 +1,89 @@
+package bsegtree
+
+import (
+	"runtime"
+	"time"
+)
+
+// Explain is how a query was executed, made by QueryExplain.
+type Explain struct {
+	Serial    bool // Query scanned the interval stack instead of traversing tree.
+	Estimated int  // Estimated result count which the path was chosen by.
+	Results   int  // Actual result count.
+
+	// NodesVisited is the count of tree nodes visited,
+	// Duplicates is the count of interval IDs found more than once and dropped.
+	// Both are 0 for serial.
+	NodesVisited int
+	Duplicates   int
+
+	// Heap allocations made by the query, may include the ones of other goroutines.
+	Allocs     uint64
+	AllocBytes uint64
+
+	Duration time.Duration
+}
+
+// QueryExplain queries [from, to] as Query does, and returns how it's done.
+// It's for debugging slow queries, it stops the world for reading memory statistics,
+// don't use it on every query.
+func (t *BSTree) QueryExplain(from, to []byte) ([]int, Explain) {
+
+	var e Explain
+	if len(t.nodes) == 0 {
+		return nil, e
+	}
+
+	fa, ta := abbreviatedRange(from, to)
+
+	if ta > t.max {
+		ta = t.max
+	}
+	if fa < t.min {
+		fa = t.min
+	}
+
+	e.Estimated = t.estimateIntervals(fa, ta)
+	e.Serial = t.policy.scan(t.count, e.Estimated)
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	mallocs, allocBytes := ms.Mallocs, ms.TotalAlloc
+
+	var result []int
+	start := time.Now()
+	if e.Serial {
+		result = t.queryScan(fa, ta, e.Estimated)
+	} else {
+		result = t.queryTree(fa, ta, e.Estimated)
+	}
+	e.Duration = time.Since(start)
+
+	runtime.ReadMemStats(&ms)
+	e.Allocs, e.AllocBytes = ms.Mallocs-mallocs, ms.TotalAlloc-allocBytes
+	e.Results = len(result)
+
+	if !e.Serial && !t.nodes[0].Disjoint(fa, ta) {
+		found := 0
+		t.explainSingle(0, fa, ta, &e.NodesVisited, &found)
+		e.Duplicates = found - len(result)
+	}
+	return result, e
+}
+
+// explainSingle traverses tree as querySingle does,
+// counting nodes visited & interval IDs found.
+func (t *BSTree) explainSingle(n int32, from, to uint64, visited, found *int) {
+
+	node := &t.nodes[n]
+	*visited++
+	*found += int(node.hi - node.lo)
+	if node.left != 0 {
+		if !t.nodes[node.right].Disjoint(from, to) {
+			t.explainSingle(node.right, from, to, visited, found)
+		}
+		if !t.nodes[node.left].Disjoint(from, to) {
+			t.explainSingle(node.left, from, to, visited, found)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package bsegtree
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestQueryExplain(t *testing.T) {
+
+	tree := New().(*BSTree)
+	if result, e := tree.QueryExplain([]byte{0}, []byte{1}); result != nil || e.Results != 0 {
+		t.Fatal("should return nothing before Build")
+	}
+
+	from, to := make([]byte, 8), make([]byte, 8)
+	for _, iv := range [][2]uint64{{0, 10}, {5, 20}} {
+		binary.BigEndian.PutUint64(from, iv[0])
+		binary.BigEndian.PutUint64(to, iv[1])
+		tree.Push(from, to)
+	}
+	tree.Build()
+
+	binary.BigEndian.PutUint64(from, 0)
+	binary.BigEndian.PutUint64(to, 20)
+
+	tree.SetPolicy(Policy{}) // Always traverse tree.
+	result, e := tree.QueryExplain(from, to)
+	if e.Serial || len(result) != 2 || e.Results != 2 {
+		t.Fatalf("wrong explain of tree query: %+v, result: %v", e, result)
+	}
+	// All nodes overlap the query, so every stored ID is found.
+	if e.NodesVisited != len(tree.nodes) || e.Duplicates != len(tree.pool)-2 {
+		t.Fatalf("wrong traversal of tree query: %+v, nodes: %d, pool: %d", e, len(tree.nodes), len(tree.pool))
+	}
+
+	tree.SetPolicy(Policy{ScanCount: 2}) // Always scan.
+	result, e = tree.QueryExplain(from, to)
+	if !e.Serial || len(result) != 2 || e.NodesVisited != 0 || e.Duplicates != 0 {
+		t.Fatalf("wrong explain of serial query: %+v, result: %v", e, result)
+	}
+	if e.Allocs == 0 {
+		t.Fatal("result allocation isn't counted")
+	}
+}
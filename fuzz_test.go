@@ -0,0 +1,207 @@
+package bsegtree
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+// fuzzIntervals decodes intervals from data:
+// each key is a length byte (mod 11, so keys are 0-10 bytes) then the key.
+// Intervals may be inverted, duplicated & empty keys are allowed.
+func fuzzIntervals(data []byte) (from, to [][]byte) {
+
+	key := func() ([]byte, bool) {
+		if len(data) == 0 {
+			return nil, false
+		}
+		n := int(data[0]) % 11
+		data = data[1:]
+		if n > len(data) {
+			n = len(data)
+		}
+		k := data[:n]
+		data = data[n:]
+		return k, true
+	}
+	for len(from) < 256 {
+		f, ok := key()
+		if !ok {
+			break
+		}
+		t, ok := key()
+		if !ok {
+			break
+		}
+		from, to = append(from, f), append(to, t)
+	}
+	return
+}
+
+func fuzzSeeds(f *testing.F) {
+	for _, s := range []struct {
+		data, from, to []byte
+	}{
+		{[]byte("\x01a\x01c\x01b\x01d"), []byte("b"), []byte("c")},
+		{[]byte("\x00\x00\x00\x01a"), nil, nil},                                                                // Empty keys.
+		{[]byte("\x01a\x01c\x01a\x01c\x01a\x01c"), []byte("a"), []byte("a")},                                   // Duplicates & point.
+		{[]byte("\x01z\x01a\x02ab\x01b"), []byte("y"), []byte("b")},                                            // Inverted.
+		{[]byte("\x0aabcdefghij\x0aabcdefghiz\x09abcdefghi\x01b"), []byte("abcdefgh"), []byte("abcdefgh\xff")}, // > 8 bytes.
+	} {
+		f.Add(s.data, s.from, s.to)
+	}
+}
+
+// fuzzTrees are all Tree implementations to check against serial.
+var fuzzTrees = map[string]func() Tree{
+	"BSTree":       New,
+	"IntervalTree": NewIntervalTree,
+	"NCList":       NewNCList,
+	"SortedSerial": NewSortedSerial,
+}
+
+func checkFuzzResult(t *testing.T, name, op string, act, exp []int) {
+	t.Helper()
+
+	sort.Ints(act)
+	for i := 1; i < len(act); i++ {
+		if act[i] == act[i-1] {
+			t.Fatalf("%s %s: repeated ID %d in %v", name, op, act[i], act)
+		}
+	}
+	sort.Ints(exp)
+	if len(act) != len(exp) {
+		t.Fatalf("%s %s: mismatched result, exp: %v, got: %v", name, op, exp, act)
+	}
+	for i := range exp {
+		if act[i] != exp[i] {
+			t.Fatalf("%s %s: mismatched result, exp: %v, got: %v", name, op, exp, act)
+		}
+	}
+}
+
+func FuzzQuery(f *testing.F) {
+	fuzzSeeds(f)
+
+	f.Fuzz(func(t *testing.T, data, from, to []byte) {
+
+		froms, tos := fuzzIntervals(data)
+		if len(froms) == 0 {
+			return
+		}
+		serial := NewSerial()
+		serial.PushArray(froms, tos)
+		exp, expRev := serial.Query(from, to), serial.Query(to, from)
+		checkFuzzResult(t, "serial", "inverted Query", expRev, exp)
+		expPoint := serial.QueryPoint(from)
+
+		check := func(name string, tree Tree) {
+			checkFuzzResult(t, name, "Query", tree.Query(from, to), exp)
+			checkFuzzResult(t, name, "inverted Query", tree.Query(to, from), exp)
+			checkFuzzResult(t, name, "QueryPoint", tree.QueryPoint(from), expPoint)
+		}
+		for name, newTree := range fuzzTrees {
+			tree := newTree()
+			tree.PushArray(froms, tos)
+			tree.Build()
+			check(name, tree)
+		}
+
+		// Tree without serial shortcut.
+		tree := New().(*BSTree)
+		tree.SetPolicy(Policy{})
+		tree.PushArray(froms, tos)
+		tree.BuildParallel(2)
+		if err := tree.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		check("BSTree (parallel, tree only)", tree)
+	})
+}
+
+func FuzzBuildSorted(f *testing.F) {
+	fuzzSeeds(f)
+
+	f.Fuzz(func(t *testing.T, data, from, to []byte) {
+
+		froms, tos := fuzzIntervals(data)
+		if len(froms) == 0 {
+			return
+		}
+		tree, serial := New().(*BSTree), NewSerial()
+		tree.PushArray(froms, tos)
+		serial.PushArray(froms, tos)
+		if err := tree.BuildSorted(); err != nil {
+			return // Unsorted input.
+		}
+		if err := tree.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		checkFuzzResult(t, "BuildSorted", "Query", tree.Query(from, to), serial.Query(from, to))
+	})
+}
+
+func FuzzMarshalBinary(f *testing.F) {
+	fuzzSeeds(f)
+	// A leaf node without intervals.
+	f.Add([]byte("BSEG\x01"+strings.Repeat("\x00", 8)+"\x01"+strings.Repeat("\x00", 7+nodeSize+8)), []byte(nil), []byte(nil))
+
+	f.Fuzz(func(t *testing.T, data, from, to []byte) {
+
+		// Arbitrary bytes must be rejected or make a valid tree.
+		tree := New().(*BSTree)
+		if err := tree.UnmarshalBinary(data); err == nil {
+			if err = tree.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			tree.Query(from, to)
+		}
+
+		froms, tos := fuzzIntervals(data)
+		if len(froms) == 0 {
+			return
+		}
+		tree.Clear()
+		tree.PushArray(froms, tos)
+		tree.Build()
+		b, err := tree.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		loaded := New().(*BSTree)
+		if err = loaded.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		checkFuzzResult(t, "loaded", "Query", loaded.Query(from, to), tree.Query(from, to))
+	})
+}
+
+func FuzzSegTree(f *testing.F) {
+	fuzzSeeds(f)
+
+	f.Fuzz(func(t *testing.T, data, from, to []byte) {
+
+		froms, tos := fuzzIntervals(data)
+		if len(froms) == 0 {
+			return
+		}
+		tree := NewSegTree[string]()
+		for i := range froms {
+			tree.Push(string(froms[i]), string(tos[i]))
+		}
+		tree.Build()
+
+		// Strings are compared fully, check by brute force.
+		q0, q1 := string(from), string(to)
+		if q0 > q1 {
+			q0, q1 = q1, q0
+		}
+		var exp []int
+		for _, iv := range tree.GetAll() {
+			if !iv.Disjoint(q0, q1) {
+				exp = append(exp, iv.ID)
+			}
+		}
+		checkFuzzResult(t, "SegTree", "Query", tree.Query(string(from), string(to)), exp)
+	})
+}
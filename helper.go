@@ -1,20 +1,26 @@
 package bsegtree
 
 import (
+	"cmp"
 	"math"
 	"sort"
 )
 
-type node struct {
-	from uint64
-	to   uint64
+// node of segment tree.
+// Nodes are stored in a flat slice and address children by index,
+// nodes[0] is the root.
+type node[K cmp.Ordered] struct {
+	from K
+	to   K
 
-	left, right *node
-
-	overlap []Interval
+	// IDs of overlap intervals are pool[lo:hi].
+	lo, hi int32
+	// Indexes of children, 0 means node is an elementary interval (leaf),
+	// because root can't be a child.
+	left, right int32
 }
 
-func (n *node) CompareTo(other Interval) int {
+func (n *node[K]) CompareTo(other Span[K]) int {
 
 	if other.From > n.to || other.To < n.from {
 		return DISJOINT
@@ -27,7 +33,7 @@ func (n *node) CompareTo(other Interval) int {
 	return INTERSECT_OR_SUPERSET
 }
 
-func (n *node) Disjoint(from, to uint64) bool {
+func (n *node[K]) Disjoint(from, to K) bool {
 
 	if from > n.to || to < n.from {
 		return true
@@ -35,14 +41,18 @@ func (n *node) Disjoint(from, to uint64) bool {
 	return false
 }
 
-type Interval struct {
+// Span is an interval [From, To] of ordered keys.
+type Span[K cmp.Ordered] struct {
 	ID   int // unique
-	From uint64
-	To   uint64
+	From K
+	To   K
 }
 
+// Interval is an interval of abbreviated keys.
+type Interval = Span[uint64]
+
 // Disjoint returns true if Segment does not overlap with interval
-func (p Interval) Disjoint(from, to uint64) bool {
+func (p Span[K]) Disjoint(from, to K) bool {
 
 	if from > p.To || to < p.From {
 		return true
@@ -51,9 +61,9 @@ func (p Interval) Disjoint(from, to uint64) bool {
 }
 
 // Endpoints returns a slice with all endpoints (sorted, unique)
-func Endpoints(base []Interval) (result []uint64, min, max uint64) {
+func Endpoints[K cmp.Ordered](base []Span[K]) (result []K, min, max K) {
 	baseLen := len(base)
-	points := make([]uint64, baseLen*2)
+	points := make([]K, baseLen*2)
 	for i, interval := range base {
 		points[i] = interval.From
 		points[i+baseLen] = interval.To
@@ -67,41 +77,41 @@ func Endpoints(base []Interval) (result []uint64, min, max uint64) {
 // Creates a slice of elementary intervals from a slice of (sorted) endpoints
 // Input: [p1, p2, ..., pn]
 // Output: [{p1 : p1}, {p1 : p2}, {p2 : p2},... , {pn : pn}
-func elementaryIntervals(endpoints []uint64) [][2]uint64 {
+func elementaryIntervals[K cmp.Ordered](endpoints []K) [][2]K {
 	if len(endpoints) == 1 {
-		return [][2]uint64{{endpoints[0], endpoints[0]}}
+		return [][2]K{{endpoints[0], endpoints[0]}}
 	}
 
-	intervals := make([][2]uint64, len(endpoints)*2-1)
+	intervals := make([][2]K, len(endpoints)*2-1)
 
 	for i := 0; i < len(endpoints); i++ {
-		intervals[i*2] = [2]uint64{endpoints[i], endpoints[i]}
+		intervals[i*2] = [2]K{endpoints[i], endpoints[i]}
 		if i < len(endpoints)-1 {
-			intervals[i*2+1] = [2]uint64{endpoints[i], endpoints[i+1]}
+			intervals[i*2+1] = [2]K{endpoints[i], endpoints[i+1]}
 		}
 	}
 	return intervals
 }
 
-type endpoints []uint64
+type endpoints[K cmp.Ordered] []K
 
-func (e endpoints) Len() int {
+func (e endpoints[K]) Len() int {
 	return len(e)
 }
 
-func (e endpoints) Less(i, j int) bool {
+func (e endpoints[K]) Less(i, j int) bool {
 
 	return e[i] < e[j]
 }
 
-func (e endpoints) Swap(i, j int) {
+func (e endpoints[K]) Swap(i, j int) {
 	e[i], e[j] = e[j], e[i]
 }
 
 // Dedup removes duplicates from a given slice
-func Dedup(e []uint64) []uint64 {
+func Dedup[K cmp.Ordered](e []K) []K {
 
-	sort.Sort(endpoints(e))
+	sort.Sort(endpoints[K](e))
 
 	cnt := len(e)
 	cntDup := 0
@@ -117,24 +127,103 @@ func Dedup(e []uint64) []uint64 {
 	return e[:cnt-cntDup]
 }
 
-// Inserts interval into given tree structure
-func (n *node) insertInterval(i Interval) {
+// insertInterval inserts interval into the subtree of nodes[n],
+// collecting overlap IDs in overlaps (indexed by node).
+func insertInterval[K cmp.Ordered](nodes []node[K], n int32, i Span[K], overlaps [][]int32) {
 
-	if n.CompareTo(i) == SUBSET {
+	if nodes[n].CompareTo(i) == SUBSET {
 		// interval of node is a subset of the specified interval or equal
-		if n.overlap == nil {
-			n.overlap = make([]Interval, 0, 2)
+		if overlaps[n] == nil {
+			overlaps[n] = make([]int32, 0, 2)
+		}
+		overlaps[n] = append(overlaps[n], int32(i.ID))
+
+	} else if nodes[n].left != 0 {
+		left, right := nodes[n].left, nodes[n].right
+		if nodes[left].CompareTo(i) != DISJOINT {
+			insertInterval(nodes, left, i, overlaps)
+		}
+		if nodes[right].CompareTo(i) != DISJOINT {
+			insertInterval(nodes, right, i, overlaps)
 		}
-		n.overlap = append(n.overlap, i)
+	}
+}
+
+// vebLayout reorders nodes in van Emde Boas layout:
+// a tree of height h is split into a top tree of height h/2 and
+// the bottom trees under it, each of them is laid out recursively and contiguously,
+// so a root-to-leaf path touches O(log(n)/log(B)) blocks of any size B.
+// Root stays at index 0.
+func vebLayout[K cmp.Ordered](nodes []node[K]) []node[K] {
 
-	} else {
-		if n.left != nil && n.left.CompareTo(i) != DISJOINT {
-			n.left.insertInterval(i)
+	order := make([]int32, 0, len(nodes))
+	var layout func(root int32, h int)
+	layout = func(root int32, h int) {
+		if h == 1 {
+			order = append(order, root)
+			return
+		}
+		top := h / 2
+		layout(root, top)
+		for _, b := range descendants(nodes, root, top, nil) {
+			layout(b, h-top)
 		}
-		if n.right != nil && n.right.CompareTo(i) != DISJOINT {
-			n.right.insertInterval(i)
+	}
+	layout(0, height(nodes, 0))
+
+	idx := make([]int32, len(nodes))
+	for i, old := range order {
+		idx[old] = int32(i)
+	}
+	veb := make([]node[K], len(nodes))
+	for i, old := range order {
+		n := nodes[old]
+		if n.left != 0 {
+			n.left, n.right = idx[n.left], idx[n.right]
 		}
+		veb[i] = n
+	}
+	return veb
+}
+
+// height returns the height of subtree of nodes[n].
+func height[K cmp.Ordered](nodes []node[K], n int32) int {
+	if nodes[n].left == 0 {
+		return 1
+	}
+	l, r := height(nodes, nodes[n].left), height(nodes, nodes[n].right)
+	if l > r {
+		return l + 1
+	}
+	return r + 1
+}
+
+// descendants appends nodes at depth d of subtree of nodes[n] to s, from left to right.
+func descendants[K cmp.Ordered](nodes []node[K], n int32, d int, s []int32) []int32 {
+	if d == 0 {
+		return append(s, n)
+	}
+	if nodes[n].left == 0 {
+		return s
+	}
+	s = descendants(nodes, nodes[n].left, d-1, s)
+	return descendants(nodes, nodes[n].right, d-1, s)
+}
+
+// packOverlaps moves overlap IDs of all nodes into one contiguous pool.
+func packOverlaps[K cmp.Ordered](nodes []node[K], overlaps [][]int32) []int32 {
+
+	total := 0
+	for _, o := range overlaps {
+		total += len(o)
 	}
+	pool := make([]int32, 0, total)
+	for i := range nodes {
+		nodes[i].lo = int32(len(pool))
+		pool = append(pool, overlaps[i]...)
+		nodes[i].hi = int32(len(pool))
+	}
+	return pool
 }
 
 // round rounds a float64 and cuts it by n.
@@ -144,4 +233,62 @@ func (n *node) insertInterval(i Interval) {
 func round(f float64, n int) float64 {
 	pow10n := math.Pow10(n)
 	return math.Trunc(f*pow10n+0.5) / pow10n
-}
\ No newline at end of file
+}
+
+// byFrom sorts intervals by From, then To, then ID.
+type byFrom []Interval
+
+func (s byFrom) Len() int {
+	return len(s)
+}
+
+func (s byFrom) Less(i, j int) bool {
+	if s[i].From != s[j].From {
+		return s[i].From < s[j].From
+	}
+	if s[i].To != s[j].To {
+		return s[i].To < s[j].To
+	}
+	return s[i].ID < s[j].ID
+}
+
+func (s byFrom) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// sortedByFrom returns a copy of base sorted by From (ties by To, then ID).
+func sortedByFrom(base []Interval) []Interval {
+	s := make([]Interval, len(base))
+	copy(s, base)
+	sort.Sort(byFrom(s))
+	return s
+}
+
+// byTo sorts intervals by To, then From, then ID.
+type byTo []Interval
+
+func (s byTo) Len() int {
+	return len(s)
+}
+
+func (s byTo) Less(i, j int) bool {
+	if s[i].To != s[j].To {
+		return s[i].To < s[j].To
+	}
+	if s[i].From != s[j].From {
+		return s[i].From < s[j].From
+	}
+	return s[i].ID < s[j].ID
+}
+
+func (s byTo) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// sortedByTo returns a copy of base sorted by To (ties by From, then ID).
+func sortedByTo(base []Interval) []Interval {
+	s := make([]Interval, len(base))
+	copy(s, base)
+	sort.Sort(byTo(s))
+	return s
+}
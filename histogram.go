@@ -0,0 +1,76 @@
+package bsegtree
+
+import "sort"
+
+// histBuckets is the max count of buckets in histogram.
+const histBuckets = 64
+
+// histogram is an equi-depth histogram of sorted values:
+// bounds[k] is the value at position pos[k], positions are evenly spaced,
+// so every bucket holds about the same count of values however they are skewed.
+type histogram struct {
+	n      int // Count of values.
+	bounds []uint64
+	pos    []int
+}
+
+// newHistogram makes histogram of n sorted values, value(i) returns the i-th one.
+func newHistogram(n int, value func(i int) uint64) histogram {
+
+	buckets := histBuckets
+	if n-1 < buckets {
+		buckets = n - 1
+	}
+	h := histogram{
+		n:      n,
+		bounds: make([]uint64, buckets+1),
+		pos:    make([]int, buckets+1),
+	}
+	for k := 0; k <= buckets; k++ {
+		p := 0
+		if buckets != 0 {
+			p = k * (n - 1) / buckets
+		}
+		h.pos[k] = p
+		h.bounds[k] = value(p)
+	}
+	return h
+}
+
+// countLE estimates count of values <= x,
+// values in a bucket are assumed to be uniformly spread.
+func (h *histogram) countLE(x uint64) float64 {
+
+	last := len(h.bounds) - 1
+	if x < h.bounds[0] {
+		return 0
+	}
+	if x >= h.bounds[last] {
+		return float64(h.n)
+	}
+	// bounds[k] <= x < bounds[k+1]
+	k := sort.Search(last+1, func(i int) bool {
+		return h.bounds[i] > x
+	}) - 1
+	lo, hi := h.bounds[k], h.bounds[k+1]
+	p := float64(h.pos[k]) + float64(x-lo)/float64(hi-lo)*float64(h.pos[k+1]-h.pos[k])
+	return p + 1
+}
+
+// countLT estimates count of values < x.
+func (h *histogram) countLT(x uint64) float64 {
+	if x == 0 {
+		return 0
+	}
+	return h.countLE(x - 1)
+}
+
+// buildHistograms makes histograms of From & To out of t.byFrom & t.byTo.
+func (t *stack) buildHistograms() {
+	t.fromHist = newHistogram(len(t.byFrom), func(i int) uint64 {
+		return t.byFrom[i].From
+	})
+	t.toHist = newHistogram(len(t.byTo), func(i int) uint64 {
+		return t.byTo[i].To
+	})
+}
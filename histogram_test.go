@@ -0,0 +1,57 @@
+package bsegtree
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestHistogramCount(t *testing.T) {
+
+	vs := []uint64{1, 3, 3, 5, 9}
+	h := newHistogram(len(vs), func(i int) uint64 {
+		return vs[i]
+	})
+	exp := map[uint64]float64{0: 0, 1: 1, 2: 1.5, 4: 3.5, 9: 5, 10: 5}
+	for x, c := range exp {
+		if act := h.countLE(x); act != c {
+			t.Fatalf("wrong count <= %d, exp: %f, got: %f", x, c, act)
+		}
+	}
+
+	h = newHistogram(1, func(i int) uint64 {
+		return 7
+	})
+	if h.countLE(6) != 0 || h.countLE(7) != 1 || h.countLT(7) != 0 {
+		t.Fatal("wrong count of histogram with one value")
+	}
+}
+
+// Intervals are crowded in a small part of key space,
+// histogram should know it.
+func TestEstimateSkewed(t *testing.T) {
+
+	rand.Seed(time.Now().UnixNano())
+
+	tree := New()
+	from, to := make([]byte, 8), make([]byte, 8)
+	for i := 0; i < 4096; i++ {
+		fn := rand.Int63n(10000)
+		if i%64 == 0 { // A few ones far away.
+			fn = rand.Int63n(1 << 40)
+		}
+		binary.BigEndian.PutUint64(from, uint64(fn))
+		binary.BigEndian.PutUint64(to, uint64(fn+10))
+		tree.Push(from, to)
+	}
+	tree.Build()
+
+	binary.BigEndian.PutUint64(from, 0)
+	binary.BigEndian.PutUint64(to, 10000)
+	exp := len(tree.Query(from, to))
+	act := tree.(*BSTree).Estimate(from, to)
+	if act < exp*9/10 || act > exp*11/10 {
+		t.Fatalf("estimate too far from actual, exp: %d, got: %d", exp, act)
+	}
+}
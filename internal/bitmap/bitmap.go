@@ -4,82 +4,42 @@
 
 package bitmap
 
-var (
-	tA = [8]byte{1, 2, 4, 8, 16, 32, 64, 128}
-	tB = [8]byte{254, 253, 251, 247, 239, 223, 191, 127}
+import (
+	"math/bits"
+	"sync"
 )
 
-func dataOrCopy(d []byte, c bool) []byte {
-	if !c {
-		return d
-	}
-	ndata := make([]byte, len(d))
-	copy(ndata, d)
-	return ndata
-}
-
-// NewSlice creates a new byteslice with length l (in bits).
-// The actual size in bits might be up to 7 bits larger because
-// they are stored in a byteslice.
-func NewSlice(l int) []byte {
-	remainder := l % 8
-	if remainder != 0 {
-		remainder = 1
-	}
-	return make([]byte, l/8+remainder)
+// NewSlice creates a new wordslice with length l (in bits).
+// The actual size in bits might be up to 63 bits larger because
+// they are stored in a wordslice.
+func NewSlice(l int) []uint64 {
+	return make([]uint64, (l+63)/64)
 }
 
 // Get returns the value of bit i from map m.
 // It doesn't check the bounds of the slice.
-func Get(m []byte, i int) bool {
-	return m[i/8]&tA[i%8] != 0
+func Get(m []uint64, i int) bool {
+	return m[i/64]&(1<<(uint(i)%64)) != 0
 }
 
 // Set sets bit i of map m to value v.
 // It doesn't check the bounds of the slice.
-func Set(m []byte, i int, v bool) {
-	index := i / 8
-	bit := i % 8
-	if v {
-		m[index] = m[index] | tA[bit]
-	} else {
-		m[index] = m[index] & tB[bit]
-	}
-}
-
-// GetBit returns the value of bit i of byte b.
-// The bit index must be between 0 and 7.
-func GetBit(b byte, i int) bool {
-	return b&tA[i] != 0
-}
-
-// SetBit sets bit i of byte b to value v.
-// The bit index must be between 0 and 7.
-func SetBit(b byte, i int, v bool) byte {
-	if v {
-		return b | tA[i]
-	}
-	return b & tB[i]
-}
-
-// SetBitRef sets bit i of byte *b to value v.
-func SetBitRef(b *byte, i int, v bool) {
+func Set(m []uint64, i int, v bool) {
 	if v {
-		*b = *b | tA[i]
+		m[i/64] |= 1 << (uint(i) % 64)
 	} else {
-		*b = *b & tB[i]
+		m[i/64] &^= 1 << (uint(i) % 64)
 	}
 }
 
-// Len returns the length (in bits) of the provided byteslice.
-// It will always be a multipile of 8 bits.
-func Len(m []byte) int {
-	return len(m) * 8
+// Len returns the length (in bits) of the provided wordslice.
+// It will always be a multipile of 64 bits.
+func Len(m []uint64) int {
+	return len(m) * 64
 }
 
-// Bitmap is a byteslice with bitmap functions.
-// Creating one form existing data is as simple as bitmap := Bitmap(data).
-type Bitmap []byte
+// Bitmap is a wordslice with bitmap functions.
+type Bitmap []uint64
 
 // New creates a new Bitmap instance with length l (in bits).
 func New(l int) Bitmap {
@@ -101,8 +61,62 @@ func (b Bitmap) Set(i int, v bool) {
 	Set(b, i, v)
 }
 
-// Data returns the data of the bitmap.
-// If copy is false the actual underlying slice will be returned.
-func (b Bitmap) Data(copy bool) []byte {
-	return dataOrCopy(b, copy)
+// Count returns the count of set bits.
+func (b Bitmap) Count() int {
+	n := 0
+	for _, w := range b {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// AppendSet appends indexes of set bits to dst in ascending order,
+// zero words are skipped at once.
+func (b Bitmap) AppendSet(dst []int) []int {
+	for i, w := range b {
+		for w != 0 {
+			dst = append(dst, i*64+bits.TrailingZeros64(w))
+			w &= w - 1
+		}
+	}
+	return dst
+}
+
+// ClearBits makes b clear, ids must be all the set bits in b.
+// It only touches words holding them, cheaper than Reset when only a few bits are set.
+func (b Bitmap) ClearBits(ids []int) {
+	for _, i := range ids {
+		b[i/64] = 0
+	}
+}
+
+// Reset sets all bits to false.
+func (b Bitmap) Reset() {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// Pool is a pool of Bitmaps, Bitmaps in it are all clear.
+type Pool struct {
+	p sync.Pool
+}
+
+// Get returns a clear Bitmap with length >= l (in bits),
+// it's trimmed to the words needed, so scanning it won't cost more for a bigger one in pool.
+// Words beyond the length are kept clear, the capacity is reused by later Get.
+func (p *Pool) Get(l int) *Bitmap {
+	if b, ok := p.p.Get().(*Bitmap); ok {
+		if words := (l + 63) / 64; cap(*b) >= words {
+			*b = (*b)[:words]
+			return b
+		}
+	}
+	b := New(l)
+	return &b
+}
+
+// Put puts b back to pool, b must be clear.
+func (p *Pool) Put(b *Bitmap) {
+	p.p.Put(b)
 }
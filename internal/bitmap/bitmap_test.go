@@ -4,68 +4,31 @@ import (
 	"testing"
 )
 
-func TestDataOrCopy(t *testing.T) {
-	data := make([]byte, 5)
-	data[0] = 1
-	d2 := dataOrCopy(data, false)
-	d2[0] = 2
-	if data[0] != 2 {
-		t.Fatal("wrong data")
-	}
-	d3 := dataOrCopy(data, true)
-	d3[0] = 3
-	if data[0] != 2 {
-		t.Fatal("wrong data")
-	}
-}
-
-func TestBitwiseOperations(t *testing.T) {
-	data := byte(0)
-	data = SetBit(data, 0, true)
-	if data != 1 {
-		t.Fatal("wrong data")
-	}
-	if GetBit(data, 0) != true {
-		t.Fatal("wrong getbit")
-	}
-	data = SetBit(data, 0, false)
-	if data != 0 {
-		t.Fatal("wrong data")
-	}
-	if GetBit(data, 0) != false {
-		t.Fatal("wrong getbit")
-	}
-}
-
 func TestBitmap(t *testing.T) {
 	bm := New(50)
 	bm.Set(30, true)
 	if bm.Get(30) != true {
 		t.Fatal("wrong GET")
 	}
-	if bm.Len() != 56 {
+	if bm.Len() != 64 {
 		t.Fatal("wrong length")
 	}
-	data := bm.Data(true)
-	if Get(data, 30) != true {
-		t.Fatal("wrong data copy")
-	}
 }
 
 func TestNewSlice(t *testing.T) {
-	bm := NewSlice(7)
+	bm := NewSlice(63)
 	if len(bm) != 1 {
 		t.Fatal("wrong length")
 	}
-	bm = NewSlice(10)
+	bm = NewSlice(65)
 	if len(bm) != 2 {
 		t.Fatal("wrong length")
 	}
 }
 
 func TestLen(t *testing.T) {
-	bitmap := []byte{0, 0, 0}
-	if Len(bitmap) != 24 {
+	bitmap := []uint64{0, 0, 0}
+	if Len(bitmap) != 192 {
 		t.Fatal("wrong length")
 	}
 }
@@ -92,6 +55,59 @@ func TestGetSet(t *testing.T) {
 	}
 }
 
+func TestAppendSetClearBits(t *testing.T) {
+	bm := New(1000)
+	ids := []int{0, 1, 63, 64, 200, 999}
+	for _, i := range ids {
+		bm.Set(i, true)
+	}
+	if bm.Count() != len(ids) {
+		t.Fatalf("wrong count, exp: %d, got: %d", len(ids), bm.Count())
+	}
+
+	act := bm.AppendSet(nil)
+	if len(act) != len(ids) {
+		t.Fatalf("wrong set bits: %v", act)
+	}
+	for i := range ids {
+		if act[i] != ids[i] {
+			t.Fatalf("wrong set bits: %v", act)
+		}
+	}
+
+	bm.ClearBits(act)
+	if bm.Count() != 0 {
+		t.Fatal("bitmap isn't clear")
+	}
+}
+
+func TestPool(t *testing.T) {
+	var p Pool
+	bm := p.Get(100)
+	if bm.Len() < 100 {
+		t.Fatal("wrong length")
+	}
+	p.Put(bm)
+	bm = p.Get(1000)
+	if bm.Len() < 1000 || bm.Count() != 0 {
+		t.Fatal("wrong bitmap from pool")
+	}
+
+	// A big bitmap is trimmed for a small request, and could be big again.
+	bm.Set(999, true)
+	bm.Reset()
+	p.Put(bm)
+	bm = p.Get(1)
+	if bm.Len() != 64 {
+		t.Fatalf("bitmap isn't trimmed, length: %d", bm.Len())
+	}
+	p.Put(bm)
+	bm = p.Get(1000)
+	if bm.Len() != 1024 || bm.Count() != 0 {
+		t.Fatal("wrong bitmap from pool")
+	}
+}
+
 func BenchmarkFuncs(b *testing.B) {
 	bm := New(1000 * 1000)
 	index := 0
@@ -0,0 +1,128 @@
+package bsegtree
+
+// itree is an augmented interval tree.
+// Intervals sorted by From are an implicit balanced binary search tree
+// (the middle one of a range is the root of it),
+// and every subtree records the max To in it for skipping subtrees on the left of query.
+//
+// Each interval is stored once (no duplication in O(log(n)) nodes like segment tree),
+// so every overlap is found once without dedup, it scales for big & wide interval sets.
+type itree struct {
+	stack
+	// Intervals sorted by From.
+	s []Interval
+	// maxTo[i] is the max To of subtree rooted at s[i].
+	maxTo []uint64
+}
+
+// NewIntervalTree returns a Tree interface with underlying augmented interval tree.
+func NewIntervalTree() Tree {
+	t := new(itree)
+	t.Clear()
+	return t
+}
+
+// Build builds interval tree out of interval stack
+func (t *itree) Build() {
+
+	if len(t.base) == 0 {
+		panic("No intervals in stack To build tree. Push intervals first")
+	}
+
+	t.s = sortedByFrom(t.base)
+	t.maxTo = make([]uint64, len(t.s))
+	t.min = t.s[0].From
+	t.max = augment(t.s, t.maxTo, 0, len(t.s))
+
+	t.byFrom = t.s
+	t.byTo = sortedByTo(t.base)
+	t.buildHistograms()
+}
+
+// augment fills maxTo of subtree of s[lo:hi], returns the max To of it.
+func augment(s []Interval, maxTo []uint64, lo, hi int) uint64 {
+
+	if lo >= hi {
+		return 0
+	}
+	mid := int(uint(lo+hi) >> 1)
+	m := s[mid].To
+	if l := augment(s, maxTo, lo, mid); l > m {
+		m = l
+	}
+	if r := augment(s, maxTo, mid+1, hi); r > m {
+		m = r
+	}
+	maxTo[mid] = m
+	return m
+}
+
+// Query interval, return interval id.
+func (t *itree) Query(from, to []byte) []int {
+	return t.QueryUint64(AbbreviatedKey(from), AbbreviatedKey(to))
+}
+
+// QueryUint64 queries interval [fa, ta] of abbreviated keys, return interval id.
+func (t *itree) QueryUint64(fa, ta uint64) []int {
+
+	if len(t.s) == 0 {
+		return nil
+	}
+
+	fa, ta = orderedRange(fa, ta)
+
+	result := make([]int, 0, t.estimateIntervals(fa, ta))
+	t.query(0, len(t.s), fa, ta, &result)
+	return result
+}
+
+// query traverse subtree of s[lo:hi] in search of overlaps
+func (t *itree) query(lo, hi int, from, to uint64, result *[]int) {
+
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if t.maxTo[mid] < from { // Whole subtree is on the left of query.
+			return
+		}
+		t.query(lo, mid, from, to, result)
+
+		i := t.s[mid]
+		if i.From > to { // The root and right subtree are on the right of query.
+			return
+		}
+		if i.To >= from {
+			*result = append(*result, i.ID)
+		}
+		lo = mid + 1
+	}
+}
+
+func (t *itree) QueryPoint(p []byte) []int {
+	return t.QueryPointUint64(AbbreviatedKey(p))
+}
+
+// QueryPointUint64 queries a point of abbreviated key, return interval id.
+func (t *itree) QueryPointUint64(p uint64) []int {
+	return t.QueryUint64(p, p)
+}
+
+// QueryInt64 queries interval [from, to] of int64, return interval id.
+func (t *itree) QueryInt64(from, to int64) []int {
+	return t.QueryUint64(int64Key(from), int64Key(to))
+}
+
+// QueryPointInt64 queries a point of int64, return interval id.
+func (t *itree) QueryPointInt64(p int64) []int {
+	return t.QueryPointUint64(int64Key(p))
+}
+
+// Clear reset Tree.
+func (t *itree) Clear() {
+	t.stack.clear()
+	t.s = nil
+	t.maxTo = nil
+}
+
+func (t *itree) Clone() Tree {
+	return &itree{stack: t.stack.clone()}
+}
@@ -0,0 +1,44 @@
+package bsegtree
+
+import (
+	"testing"
+)
+
+func TestIntervalTreeEqualSerial(t *testing.T) {
+	testEqualSerialSameLenInterval(t, NewIntervalTree)
+	testEqualSerialInterval(t, NewIntervalTree)
+}
+
+func TestIntervalTreeMinimal(t *testing.T) {
+	tree := NewIntervalTree()
+	tree.Push([]byte("3"), []byte("7"))
+	tree.Build()
+	if result := tree.Query([]byte("1"), []byte("2")); len(result) != 0 {
+		t.Errorf("fail query minimal tree for (1, 2)")
+	}
+	if result := tree.Query([]byte("2"), []byte("3")); len(result) != 1 {
+		t.Errorf("fail query minimal tree for (2, 3)")
+	}
+
+	ct := tree.Clone()
+	ct.Build()
+	if result := ct.QueryPoint([]byte("7")); len(result) != 1 {
+		t.Errorf("fail query cloned tree for 7")
+	}
+}
+
+// Backends share only the interval stack with BSTree,
+// methods working on segment tree mustn't be promoted to them.
+func TestBackendsNoSegmentTreeMethods(t *testing.T) {
+	for _, tree := range []Tree{NewSerial(), NewIntervalTree(), NewNCList(), NewSortedSerial()} {
+		if _, ok := tree.(interface{ BuildSorted() error }); ok {
+			t.Fatalf("%T has BuildSorted", tree)
+		}
+		if _, ok := tree.(interface{ Stats() Stats }); ok {
+			t.Fatalf("%T has Stats", tree)
+		}
+		if _, ok := tree.(interface{ MarshalBinary() ([]byte, error) }); ok {
+			t.Fatalf("%T has MarshalBinary", tree)
+		}
+	}
+}
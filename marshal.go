@@ -0,0 +1,151 @@
+package bsegtree
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidData is returned by UnmarshalBinary when data isn't made by MarshalBinary.
+var ErrInvalidData = errors.New("bsegtree: invalid tree data")
+
+// Serialized tree:
+//
+//	magic "BSEG" | version (1 byte) | count (8) | intervals (From & To, 16 each) |
+//	nodes count (8) | nodes (from, to, lo, hi, left, right: 32 each) | pool length (8) | pool (4 each)
+//
+// All numbers are little endian.
+const (
+	marshalMagic   = "BSEG"
+	marshalVersion = 1
+	nodeSize       = 32
+)
+
+// MarshalBinary encodes interval stack & built tree (if there is) to bytes,
+// it's for building tree offline and loading it by UnmarshalBinary.
+func (t *BSTree) MarshalBinary() ([]byte, error) {
+
+	size := len(marshalMagic) + 1 + 8 + len(t.base)*16 + 8 + len(t.nodes)*nodeSize + 8 + len(t.pool)*4
+	b := make([]byte, 0, size)
+	b = append(b, marshalMagic...)
+	b = append(b, marshalVersion)
+
+	le := binary.LittleEndian
+	var buf [8]byte
+	putU64 := func(v uint64) {
+		le.PutUint64(buf[:], v)
+		b = append(b, buf[:]...)
+	}
+	putI32 := func(v int32) {
+		le.PutUint32(buf[:4], uint32(v))
+		b = append(b, buf[:4]...)
+	}
+
+	putU64(uint64(len(t.base)))
+	for _, iv := range t.base {
+		putU64(iv.From)
+		putU64(iv.To)
+	}
+	putU64(uint64(len(t.nodes)))
+	for i := range t.nodes {
+		n := &t.nodes[i]
+		putU64(n.from)
+		putU64(n.to)
+		putI32(n.lo)
+		putI32(n.hi)
+		putI32(n.left)
+		putI32(n.right)
+	}
+	putU64(uint64(len(t.pool)))
+	for _, id := range t.pool {
+		putI32(id)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary replaces intervals & tree by the ones encoded by MarshalBinary.
+// The loaded tree is checked by Validate, policy & observer are kept.
+// t isn't changed if it returns an error.
+func (t *BSTree) UnmarshalBinary(data []byte) error {
+
+	if len(data) < len(marshalMagic)+1 || string(data[:len(marshalMagic)]) != marshalMagic {
+		return ErrInvalidData
+	}
+	if v := data[len(marshalMagic)]; v != marshalVersion {
+		return fmt.Errorf("bsegtree: unsupported tree data version %d", v)
+	}
+	data = data[len(marshalMagic)+1:]
+
+	le := binary.LittleEndian
+	var short bool
+	u64 := func() uint64 {
+		if len(data) < 8 {
+			short = true
+			return 0
+		}
+		v := le.Uint64(data)
+		data = data[8:]
+		return v
+	}
+	i32 := func() int32 {
+		if len(data) < 4 {
+			short = true
+			return 0
+		}
+		v := int32(le.Uint32(data))
+		data = data[4:]
+		return v
+	}
+	// length reads a count of elements taking size bytes each.
+	length := func(size int) int {
+		n := u64()
+		if n > uint64(len(data)/size) {
+			short = true
+			return 0
+		}
+		return int(n)
+	}
+
+	base := make([]Interval, length(16))
+	for i := range base {
+		base[i] = Interval{ID: i, From: u64(), To: u64()}
+	}
+	nodes := make([]node[uint64], length(nodeSize))
+	for i := range nodes {
+		n := &nodes[i]
+		n.from, n.to = u64(), u64()
+		n.lo, n.hi, n.left, n.right = i32(), i32(), i32(), i32()
+	}
+	pool := make([]int32, length(4))
+	for i := range pool {
+		pool[i] = i32()
+	}
+	if short || len(data) != 0 {
+		return ErrInvalidData
+	}
+
+	if len(base) == 0 && len(nodes) != 0 { // Tree without intervals.
+		return ErrInvalidData
+	}
+
+	// Loaded into a new tree, t is kept as it was if data is invalid.
+	nt := new(BSTree)
+	nt.Clear()
+	for _, iv := range base {
+		nt.push(iv.From, iv.To)
+	}
+	if len(nodes) != 0 {
+		nt.nodes, nt.pool = nodes, pool
+		if err := nt.Validate(); err != nil {
+			return err
+		}
+		nt.min, nt.max = nodes[0].from, nodes[0].to
+		nt.makeColumns()
+		nt.byFrom = sortedByFrom(nt.base)
+		nt.byTo = sortedByTo(nt.base)
+		nt.buildHistograms()
+	}
+	nt.policy, nt.observer = t.policy, t.observer // Loading isn't a Clear.
+	*t = *nt
+	return nil
+}
@@ -0,0 +1,89 @@
+package bsegtree
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMarshalBinary(t *testing.T) {
+
+	rand.Seed(time.Now().UnixNano())
+
+	tree := New().(*BSTree)
+	from, to := make([]byte, 8), make([]byte, 8)
+	for i := 0; i < 512; i++ {
+		f := rand.Uint64() % 10000
+		binary.BigEndian.PutUint64(from, f)
+		binary.BigEndian.PutUint64(to, f+rand.Uint64()%500)
+		tree.Push(from, to)
+	}
+
+	// Not built.
+	data, err := tree.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := New().(*BSTree)
+	if err = loaded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.GetAll(), tree.GetAll()) || len(loaded.nodes) != 0 {
+		t.Fatal("mismatched intervals of unbuilt tree")
+	}
+
+	tree.Build()
+	data, err = tree.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = loaded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.nodes, tree.nodes) || !reflect.DeepEqual(loaded.pool, tree.pool) {
+		t.Fatal("mismatched tree")
+	}
+	if loaded.Stats() != tree.Stats() {
+		t.Fatalf("mismatched stats, exp: %+v, got: %+v", tree.Stats(), loaded.Stats())
+	}
+	for i := 0; i < 256; i++ {
+		f := rand.Uint64() % 10500
+		binary.BigEndian.PutUint64(from, f)
+		binary.BigEndian.PutUint64(to, f+rand.Uint64()%1000)
+		if !reflect.DeepEqual(loaded.Query(from, to), tree.Query(from, to)) {
+			t.Fatal("mismatched query result")
+		}
+	}
+
+	for name, bad := range map[string][]byte{
+		"empty":     nil,
+		"magic":     append([]byte("BSEX"), data[4:]...),
+		"version":   append(append([]byte("BSEG"), 0), data[5:]...),
+		"truncated": data[:len(data)-1],
+		"trailing":  append(append([]byte(nil), data...), 0),
+		"no intervals": append(append([]byte("BSEG\x01"), make([]byte, 8)...),
+			append([]byte{1}, make([]byte, 7+nodeSize+8)...)...),
+	} {
+		if err = New().(*BSTree).UnmarshalBinary(bad); err == nil {
+			t.Fatalf("%s data is accepted", name)
+		}
+	}
+
+	// ID out of range in the last pool entry.
+	bad := append([]byte(nil), data...)
+	binary.LittleEndian.PutUint32(bad[len(bad)-4:], 1<<20)
+	if err = loaded.UnmarshalBinary(bad); err == nil {
+		t.Fatal("corrupted tree is accepted")
+	}
+	// Failed loading keeps the tree.
+	if !reflect.DeepEqual(loaded.GetAll(), tree.GetAll()) || !reflect.DeepEqual(loaded.nodes, tree.nodes) {
+		t.Fatal("tree is changed by failed loading")
+	}
+	binary.BigEndian.PutUint64(from, 5000)
+	binary.BigEndian.PutUint64(to, 6000)
+	if !reflect.DeepEqual(loaded.Query(from, to), tree.Query(from, to)) {
+		t.Fatal("mismatched query result after failed loading")
+	}
+}
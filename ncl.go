@@ -0,0 +1,182 @@
+package bsegtree
+
+import "sort"
+
+// nclist is a nested containment list.
+// Intervals which aren't contained by others are the top list,
+// intervals contained by an interval are in its sublist, and so on.
+// Every list is stored contiguously and sorted by From,
+// there is no containment in a list, so To is sorted too,
+// then the first overlap in a list could be found by binary search.
+//
+// It's good at datasets where intervals are mostly nested (e.g. hierarchical key prefixes).
+type nclist struct {
+	stack
+	// Intervals of all lists, top list is s[:top].
+	s   []Interval
+	top int32
+	// sub[i] is the sublist of s[i]: s[sub[i][0]:sub[i][1]].
+	sub [][2]int32
+}
+
+// NewNCList returns a Tree interface with underlying nested containment list.
+func NewNCList() Tree {
+	t := new(nclist)
+	t.Clear()
+	return t
+}
+
+// byContainer sorts intervals by From, then To descending (container first), then ID.
+type byContainer []Interval
+
+func (s byContainer) Len() int {
+	return len(s)
+}
+
+func (s byContainer) Less(i, j int) bool {
+	if s[i].From != s[j].From {
+		return s[i].From < s[j].From
+	}
+	if s[i].To != s[j].To {
+		return s[i].To > s[j].To
+	}
+	return s[i].ID < s[j].ID
+}
+
+func (s byContainer) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// Build builds nested containment list out of interval stack
+func (t *nclist) Build() {
+
+	if len(t.base) == 0 {
+		panic("No intervals in stack To build tree. Push intervals first")
+	}
+
+	n := len(t.base)
+	sorted := make([]Interval, n)
+	copy(sorted, t.base)
+	sort.Sort(byContainer(sorted))
+
+	// Find the container (parent) of each interval,
+	// it's the nearest one in stack which ends after it.
+	parent := make([]int32, n)
+	cnt := make([]int32, n) // Count of children.
+	top := int32(0)
+	stack := make([]int32, 0, 16)
+	for i := range sorted {
+		for len(stack) > 0 && sorted[stack[len(stack)-1]].To < sorted[i].To {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			parent[i] = -1
+			top++
+		} else {
+			parent[i] = stack[len(stack)-1]
+			cnt[parent[i]]++
+		}
+		stack = append(stack, int32(i))
+	}
+
+	// Lay lists out: top list first, then sublists in order of their containers.
+	start := make([]int32, n)
+	off := top
+	for i := range sorted {
+		start[i] = off
+		off += cnt[i]
+	}
+	t.s = make([]Interval, n)
+	t.sub = make([][2]int32, n)
+	pos := make([]int32, n)
+	next := int32(0) // Next position in top list.
+	for i := range sorted {
+		p := parent[i]
+		if p == -1 {
+			pos[i] = next
+			next++
+		} else {
+			pos[i] = start[p] + t.sub[pos[p]][1] - t.sub[pos[p]][0]
+			t.sub[pos[p]][1]++
+		}
+		t.s[pos[i]] = sorted[i]
+		t.sub[pos[i]] = [2]int32{start[i], start[i]}
+	}
+	t.top = top
+
+	t.min = sorted[0].From
+	t.max = 0
+	for _, i := range t.s[:top] {
+		if i.To > t.max {
+			t.max = i.To
+		}
+	}
+	t.byFrom = sortedByFrom(t.base)
+	t.byTo = sortedByTo(t.base)
+	t.buildHistograms()
+}
+
+// Query interval, return interval id.
+func (t *nclist) Query(from, to []byte) []int {
+	return t.QueryUint64(AbbreviatedKey(from), AbbreviatedKey(to))
+}
+
+// QueryUint64 queries interval [fa, ta] of abbreviated keys, return interval id.
+func (t *nclist) QueryUint64(fa, ta uint64) []int {
+
+	if len(t.s) == 0 {
+		return nil
+	}
+
+	fa, ta = orderedRange(fa, ta)
+
+	result := make([]int, 0, t.estimateIntervals(fa, ta))
+	t.query(0, t.top, fa, ta, &result)
+	return result
+}
+
+// query searches list s[lo:hi] and sublists in it for overlaps
+func (t *nclist) query(lo, hi int32, from, to uint64, result *[]int) {
+
+	// To is sorted in a list, skip intervals end before from.
+	k := lo + int32(sort.Search(int(hi-lo), func(j int) bool {
+		return t.s[lo+int32(j)].To >= from
+	}))
+	for ; k < hi && t.s[k].From <= to; k++ {
+		*result = append(*result, t.s[k].ID)
+		if sub := t.sub[k]; sub[0] < sub[1] {
+			t.query(sub[0], sub[1], from, to, result)
+		}
+	}
+}
+
+func (t *nclist) QueryPoint(p []byte) []int {
+	return t.QueryPointUint64(AbbreviatedKey(p))
+}
+
+// QueryPointUint64 queries a point of abbreviated key, return interval id.
+func (t *nclist) QueryPointUint64(p uint64) []int {
+	return t.QueryUint64(p, p)
+}
+
+// QueryInt64 queries interval [from, to] of int64, return interval id.
+func (t *nclist) QueryInt64(from, to int64) []int {
+	return t.QueryUint64(int64Key(from), int64Key(to))
+}
+
+// QueryPointInt64 queries a point of int64, return interval id.
+func (t *nclist) QueryPointInt64(p int64) []int {
+	return t.QueryPointUint64(int64Key(p))
+}
+
+// Clear reset Tree.
+func (t *nclist) Clear() {
+	t.stack.clear()
+	t.s = nil
+	t.top = 0
+	t.sub = nil
+}
+
+func (t *nclist) Clone() Tree {
+	return &nclist{stack: t.stack.clone()}
+}
@@ -0,0 +1,50 @@
+package bsegtree
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestNCListEqualSerial(t *testing.T) {
+	testEqualSerialSameLenInterval(t, NewNCList)
+	testEqualSerialInterval(t, NewNCList)
+}
+
+// Intervals are nested like key prefixes: each level splits its parent into 4 parts.
+func TestNCListNestedEqualSerial(t *testing.T) {
+
+	rand.Seed(time.Now().UnixNano())
+
+	tree, serial := NewNCList(), NewSerial()
+	from, to := make([]byte, 8), make([]byte, 8)
+	var push func(lo, hi uint64, depth int)
+	push = func(lo, hi uint64, depth int) {
+		binary.BigEndian.PutUint64(from, lo)
+		binary.BigEndian.PutUint64(to, hi)
+		tree.Push(from, to)
+		serial.Push(from, to)
+		if depth == 0 {
+			return
+		}
+		step := (hi - lo) / 4
+		for i := uint64(0); i < 4; i++ {
+			if rand.Intn(4) != 0 {
+				push(lo+i*step, lo+(i+1)*step-1, depth-1)
+			}
+		}
+	}
+	push(0, 1<<20, 6)
+	tree.Build()
+
+	for i := 0; i < 1024; i++ {
+		fn := rand.Int63n(1 << 20)
+		tn := fn + rand.Int63n(1<<12)
+		binary.BigEndian.PutUint64(from, uint64(fn))
+		binary.BigEndian.PutUint64(to, uint64(tn))
+
+		cmpQueryWithSerial(t, tree, serial, from, to, 0, false, false)
+		cmpQueryWithSerial(t, tree, serial, from, nil, 0, false, true)
+	}
+}
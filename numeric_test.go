@@ -0,0 +1,83 @@
+package bsegtree
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+// Numeric keys must work as the same big endian encoded bytes keys on all implementations.
+func TestNumericKeys(t *testing.T) {
+
+	rand.Seed(time.Now().UnixNano())
+
+	int64Bytes := func(v int64) []byte {
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, uint64(v)^1<<63)
+		return b
+	}
+	rnd := func() int64 {
+		return rand.Int63n(20000) - 10000
+	}
+
+	for name, newTree := range map[string]func() Tree{
+		"BSTree":       New,
+		"serial":       NewSerial,
+		"IntervalTree": NewIntervalTree,
+		"NCList":       NewNCList,
+		"SortedSerial": NewSortedSerial,
+	} {
+		tree, ref := newTree().(NumericTree), NewSerial()
+		for i := 0; i < 512; i++ {
+			from, to := rnd(), rnd() // May be inverted.
+			tree.PushInt64(from, to)
+			ref.Push(int64Bytes(from), int64Bytes(to))
+		}
+		tree.PushUint64(1<<63+20000, 1<<63+20001) // Same as PushInt64(20000, 20001).
+		ref.Push(int64Bytes(20000), int64Bytes(20001))
+		tree.Build()
+		ref.Build()
+
+		for i := 0; i < 256; i++ {
+			from, to := rnd(), rnd()
+			p := rnd()
+			pairs := [][2][]int{
+				{tree.QueryInt64(from, to), ref.Query(int64Bytes(from), int64Bytes(to))},
+				{tree.QueryUint64(int64Key(from), int64Key(to)), ref.Query(int64Bytes(from), int64Bytes(to))},
+				{tree.QueryPointInt64(p), ref.QueryPoint(int64Bytes(p))},
+				{tree.QueryPointUint64(int64Key(p)), ref.QueryPoint(int64Bytes(p))},
+				{tree.QueryInt64(20000, 30000), ref.Query(int64Bytes(20000), int64Bytes(30000))},
+			}
+			for _, pair := range pairs {
+				act, exp := pair[0], pair[1]
+				sort.Ints(act)
+				sort.Ints(exp)
+				if len(act) != len(exp) {
+					t.Fatalf("%s: mismatched result, exp: %v, got: %v", name, exp, act)
+				}
+				for j := range exp {
+					if act[j] != exp[j] {
+						t.Fatalf("%s: mismatched result, exp: %v, got: %v", name, exp, act)
+					}
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkQueryPointUint64(b *testing.B) {
+
+	tree := New().(NumericTree)
+	for i := 0; i < 1024; i++ {
+		f := uint64(rand.Int63n(1000000))
+		tree.PushUint64(f, f+uint64(rand.Int63n(1000)))
+	}
+	tree.Build()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.QueryPointUint64(uint64(i % 1000000))
+	}
+}
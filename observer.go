@@ -0,0 +1,100 @@
+package bsegtree
+
+import (
+	"expvar"
+	"time"
+)
+
+// Observer is notified of BSTree operations, e.g. for exporting metrics.
+// Methods are called synchronously, they should be fast & safe for concurrent use
+// if the tree is queried concurrently.
+type Observer interface {
+	// ObserveQuery is called after each Query/QueryPoint on built tree,
+	// serial is true if Query scanned the interval stack instead of traversing tree.
+	ObserveQuery(d time.Duration, results int, serial bool)
+	// ObserveBuild is called after each Build/BuildParallel/BuildSorted/BulkLoad/BuildFrom.
+	ObserveBuild(d time.Duration, intervals int)
+	// ObserveClear is called after each Clear.
+	ObserveClear()
+}
+
+// NewWithObserver creates a Tree like New, and o observes it.
+// A tree without observer costs only a nil check.
+func NewWithObserver(o Observer) Tree {
+	t := New().(*BSTree)
+	t.observer = o
+	return t
+}
+
+// observeQuery queries [from, to] by serial or tree & tells observer.
+func (t *BSTree) observeQuery(from, to uint64, cnt int, serial bool) []int {
+
+	start := time.Now()
+	var result []int
+	if serial {
+		result = t.queryScan(from, to, cnt)
+	} else {
+		result = t.queryTree(from, to, cnt)
+	}
+	t.observer.ObserveQuery(time.Since(start), len(result), serial)
+	return result
+}
+
+// observeBuild tells observer the build started at start is done.
+func (t *BSTree) observeBuild(start time.Time) {
+	t.observer.ObserveBuild(time.Since(start), t.count)
+}
+
+// ExpvarObserver is an Observer publishing counters by expvar,
+// as a map with keys:
+//
+//	queries, serial_queries, query_ns (total), results (total),
+//	builds, build_ns (total), last_build_ns, last_build_intervals, clears
+type ExpvarObserver struct {
+	queries, serialQueries, queryNanos, results            *expvar.Int
+	builds, buildNanos, lastBuildNanos, lastBuildIntervals *expvar.Int
+	clears                                                 *expvar.Int
+}
+
+// NewExpvarObserver creates an ExpvarObserver publishing the map as name,
+// it panics if name is already registered (see expvar.Publish).
+func NewExpvarObserver(name string) *ExpvarObserver {
+
+	m := expvar.NewMap(name)
+	newInt := func(key string) *expvar.Int {
+		v := new(expvar.Int)
+		m.Set(key, v)
+		return v
+	}
+	return &ExpvarObserver{
+		queries:            newInt("queries"),
+		serialQueries:      newInt("serial_queries"),
+		queryNanos:         newInt("query_ns"),
+		results:            newInt("results"),
+		builds:             newInt("builds"),
+		buildNanos:         newInt("build_ns"),
+		lastBuildNanos:     newInt("last_build_ns"),
+		lastBuildIntervals: newInt("last_build_intervals"),
+		clears:             newInt("clears"),
+	}
+}
+
+func (o *ExpvarObserver) ObserveQuery(d time.Duration, results int, serial bool) {
+	o.queries.Add(1)
+	if serial {
+		o.serialQueries.Add(1)
+	}
+	o.queryNanos.Add(int64(d))
+	o.results.Add(int64(results))
+}
+
+func (o *ExpvarObserver) ObserveBuild(d time.Duration, intervals int) {
+	o.builds.Add(1)
+	o.buildNanos.Add(int64(d))
+	o.lastBuildNanos.Set(int64(d))
+	o.lastBuildIntervals.Set(int64(intervals))
+}
+
+func (o *ExpvarObserver) ObserveClear() {
+	o.clears.Add(1)
+}
@@ -0,0 +1,105 @@
+package bsegtree
+
+import (
+	"encoding/binary"
+	"expvar"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	queries, serial, results, builds, buildIntervals, clears int
+}
+
+func (r *recorder) ObserveQuery(d time.Duration, results int, serial bool) {
+	r.queries++
+	r.results += results
+	if serial {
+		r.serial++
+	}
+}
+
+func (r *recorder) ObserveBuild(d time.Duration, intervals int) {
+	r.builds++
+	r.buildIntervals = intervals
+}
+
+func (r *recorder) ObserveClear() {
+	r.clears++
+}
+
+func TestObserver(t *testing.T) {
+
+	r := new(recorder)
+	tree := NewWithObserver(r).(*BSTree)
+	if r.clears != 0 {
+		t.Fatal("constructor shouldn't be observed")
+	}
+
+	from, to := make([]byte, 8), make([]byte, 8)
+	for i := 0; i < 4; i++ {
+		binary.BigEndian.PutUint64(from, uint64(i))
+		binary.BigEndian.PutUint64(to, uint64(i+1))
+		tree.Push(from, to)
+	}
+	tree.Build()
+	if r.builds != 1 || r.buildIntervals != 4 {
+		t.Fatalf("wrong build observation: %+v", r)
+	}
+	if err := tree.BuildSorted(); err != nil {
+		t.Fatal(err)
+	}
+	if r.builds != 2 {
+		t.Fatalf("BuildSorted isn't observed: %+v", r)
+	}
+
+	binary.BigEndian.PutUint64(from, 1)
+	binary.BigEndian.PutUint64(to, 2)
+	tree.SetPolicy(Policy{})
+	tree.Query(from, to)
+	tree.SetPolicy(Policy{ScanCount: 4})
+	tree.QueryPoint(from)
+	if r.queries != 2 || r.serial != 1 || r.results != 3+2 {
+		t.Fatalf("wrong query observation: %+v", r)
+	}
+
+	c := tree.Clone().(*BSTree)
+	c.Clear()
+	if r.clears != 1 {
+		t.Fatalf("clone isn't observed: %+v", r)
+	}
+}
+
+// expvarRuns makes expvar name unique for each run (e.g. go test -count=2),
+// publishing a name twice panics.
+var expvarRuns int
+
+func TestExpvarObserver(t *testing.T) {
+
+	expvarRuns++
+	name := fmt.Sprintf("bsegtree_test_%d", expvarRuns)
+	o := NewExpvarObserver(name)
+	o.ObserveQuery(time.Microsecond, 3, true)
+	o.ObserveQuery(time.Microsecond, 1, false)
+	o.ObserveBuild(time.Millisecond, 10)
+	o.ObserveClear()
+
+	m := expvar.Get(name).(*expvar.Map)
+	exp := map[string]string{
+		"queries":              "2",
+		"serial_queries":       "1",
+		"query_ns":             "2000",
+		"results":              "4",
+		"builds":               "1",
+		"build_ns":             "1000000",
+		"last_build_ns":        "1000000",
+		"last_build_intervals": "10",
+		"clears":               "1",
+	}
+	for k, v := range exp {
+		if act := m.Get(k).String(); act != v {
+			t.Fatalf("wrong %s, exp: %s, got: %s", k, v, act)
+		}
+	}
+}
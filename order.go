@@ -0,0 +1,130 @@
+package bsegtree
+
+import "sort"
+
+// Floor returns the interval with the greatest To <= key,
+// ties are broken by the greatest From (the closest one).
+// ok is false if there is no such interval.
+//
+// Build the tree before using it.
+func (t *BSTree) Floor(key []byte) (i Interval, ok bool) {
+
+	k := AbbreviatedKey(key)
+	n := sort.Search(len(t.byTo), func(j int) bool {
+		return t.byTo[j].To > k
+	})
+	if n == 0 {
+		return Interval{}, false
+	}
+	return t.byTo[n-1], true
+}
+
+// Ceil returns the interval with the least From >= key,
+// ties are broken by the least To (the closest one).
+// ok is false if there is no such interval.
+//
+// Build the tree before using it.
+func (t *BSTree) Ceil(key []byte) (i Interval, ok bool) {
+
+	n := seekGE(t.byFrom, AbbreviatedKey(key))
+	if n == len(t.byFrom) {
+		return Interval{}, false
+	}
+	return t.byFrom[n], true
+}
+
+// seekGE returns the index of the first interval in s (sorted by From)
+// with From >= k, len(s) if there isn't any.
+func seekGE(s []Interval, k uint64) int {
+	return sort.Search(len(s), func(j int) bool {
+		return s[j].From >= k
+	})
+}
+
+// Iterator iterates intervals ordered by From (ties by To, then ID),
+// in both directions.
+//
+// e.g.
+//
+//	for it := t.Iter(); it.Next(); {
+//		iv := it.Interval()
+//	}
+type Iterator struct {
+	s []Interval
+	i int // Position in s, out of [0, len(s)) means invalid.
+}
+
+// Iter returns an unpositioned Iterator of the tree,
+// the first Next moves it to the first interval,
+// use Last & Prev for reverse iteration.
+//
+// Build the tree before using it.
+func (t *BSTree) Iter() *Iterator {
+	return &Iterator{s: t.byFrom, i: -1}
+}
+
+// Seek returns an Iterator positioned at the first interval with From >= key.
+//
+// Build the tree before using it.
+func (t *BSTree) Seek(key []byte) *Iterator {
+	it := t.Iter()
+	it.SeekGE(key)
+	return it
+}
+
+// First moves the iterator to the first interval,
+// returns false if there isn't any.
+func (it *Iterator) First() bool {
+	it.i = 0
+	return it.Valid()
+}
+
+// Last moves the iterator to the last interval,
+// returns false if there isn't any.
+func (it *Iterator) Last() bool {
+	it.i = len(it.s) - 1
+	return it.Valid()
+}
+
+// SeekGE moves the iterator to the first interval with From >= key,
+// returns false if there isn't any.
+func (it *Iterator) SeekGE(key []byte) bool {
+	it.i = seekGE(it.s, AbbreviatedKey(key))
+	return it.Valid()
+}
+
+// SeekLT moves the iterator to the last interval with From < key,
+// returns false if there isn't any.
+func (it *Iterator) SeekLT(key []byte) bool {
+	it.i = seekGE(it.s, AbbreviatedKey(key)) - 1
+	return it.Valid()
+}
+
+// Next moves the iterator to the next interval,
+// returns false if it's exhausted.
+func (it *Iterator) Next() bool {
+	if it.i < len(it.s) {
+		it.i++
+	}
+	return it.Valid()
+}
+
+// Prev moves the iterator to the previous interval,
+// returns false if it's exhausted.
+func (it *Iterator) Prev() bool {
+	if it.i >= 0 {
+		it.i--
+	}
+	return it.Valid()
+}
+
+// Valid returns true if the iterator is positioned at an interval.
+func (it *Iterator) Valid() bool {
+	return it.i >= 0 && it.i < len(it.s)
+}
+
+// Interval returns the current interval.
+// It's only valid when Valid returns true.
+func (it *Iterator) Interval() Interval {
+	return it.s[it.i]
+}
@@ -0,0 +1,111 @@
+package bsegtree
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestFloorCeil(t *testing.T) {
+
+	rand.Seed(time.Now().UnixNano())
+
+	tree := New()
+	from, to := make([]byte, 8), make([]byte, 8)
+	for i := 0; i < 256; i++ {
+		fn := rand.Int63n(100000)
+		tn := fn + rand.Int63n(1000)
+		binary.BigEndian.PutUint64(from, uint64(fn))
+		binary.BigEndian.PutUint64(to, uint64(tn))
+		tree.Push(from, to)
+	}
+	tree.Build()
+	bt := tree.(*BSTree)
+
+	key := make([]byte, 8)
+	for i := 0; i < 1024; i++ {
+		k := uint64(rand.Int63n(102000))
+		binary.BigEndian.PutUint64(key, k)
+
+		var floor, ceil Interval
+		hasFloor, hasCeil := false, false
+		for _, iv := range tree.GetAll() {
+			if iv.To <= k && (!hasFloor || iv.To > floor.To || (iv.To == floor.To && iv.From > floor.From)) {
+				floor, hasFloor = iv, true
+			}
+			if iv.From >= k && (!hasCeil || iv.From < ceil.From || (iv.From == ceil.From && iv.To < ceil.To)) {
+				ceil, hasCeil = iv, true
+			}
+		}
+
+		act, ok := bt.Floor(key)
+		if ok != hasFloor || (ok && (act.From != floor.From || act.To != floor.To)) {
+			t.Fatalf("wrong floor of %d, exp: %v, got: %v", k, floor, act)
+		}
+		act, ok = bt.Ceil(key)
+		if ok != hasCeil || (ok && (act.From != ceil.From || act.To != ceil.To)) {
+			t.Fatalf("wrong ceil of %d, exp: %v, got: %v", k, ceil, act)
+		}
+	}
+}
+
+func TestIterator(t *testing.T) {
+
+	rand.Seed(time.Now().UnixNano())
+
+	tree := New()
+	from, to := make([]byte, 8), make([]byte, 8)
+	for i := 0; i < 256; i++ {
+		fn := rand.Int63n(1000)
+		tn := fn + rand.Int63n(100)
+		binary.BigEndian.PutUint64(from, uint64(fn))
+		binary.BigEndian.PutUint64(to, uint64(tn))
+		tree.Push(from, to)
+	}
+	tree.Build()
+	bt := tree.(*BSTree)
+
+	var all []Interval
+	for it := bt.Iter(); it.Next(); {
+		all = append(all, it.Interval())
+	}
+	if len(all) != 256 {
+		t.Fatalf("wrong iteration count, exp: %d, got: %d", 256, len(all))
+	}
+	for i := 1; i < len(all); i++ {
+		if byFrom(all).Less(i, i-1) {
+			t.Fatalf("wrong order: %v before %v", all[i-1], all[i])
+		}
+	}
+
+	n := len(all)
+	it := bt.Iter()
+	for ok := it.Last(); ok; ok = it.Prev() {
+		n--
+		if it.Interval() != all[n] {
+			t.Fatalf("wrong reverse iteration, exp: %v, got: %v", all[n], it.Interval())
+		}
+	}
+	if n != 0 {
+		t.Fatal("reverse iteration stopped early")
+	}
+
+	key := make([]byte, 8)
+	for i := 0; i < 1024; i++ {
+		k := uint64(rand.Int63n(1100))
+		binary.BigEndian.PutUint64(key, k)
+
+		exp := 0
+		for exp < len(all) && all[exp].From < k {
+			exp++
+		}
+		it := bt.Seek(key)
+		if it.Valid() != (exp < len(all)) || (it.Valid() && it.Interval() != all[exp]) {
+			t.Fatalf("wrong seek of %d", k)
+		}
+		if it.SeekLT(key) != (exp > 0) || (it.Valid() && it.Interval() != all[exp-1]) {
+			t.Fatalf("wrong reverse seek of %d", k)
+		}
+	}
+}
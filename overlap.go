@@ -0,0 +1,84 @@
+package bsegtree
+
+// OverlappingPairs calls fn for every pair of intervals in stack which overlap.
+// a is the one with the smaller From (ties by To, then ID).
+// Iteration stops when fn returns false.
+//
+// It sweeps intervals ordered by From, which costs O(n*log(n) + k) for k pairs,
+// and it doesn't need Build.
+func (t *BSTree) OverlappingPairs(fn func(a, b int) bool) {
+
+	active := make([]Interval, 0, 16)
+	for _, cur := range sortedByFrom(t.base) {
+		var ok bool
+		active, ok = sweep(active, cur, func(o Interval) bool {
+			return fn(o.ID, cur.ID)
+		})
+		if !ok {
+			return
+		}
+		active = append(active, cur)
+	}
+}
+
+// Join calls fn for every pair of intervals which overlap,
+// idA is from a and idB is from b.
+// Iteration stops when fn returns false.
+//
+// It's a merged sweep over intervals of both trees ordered by From,
+// so neither a nor b need to be built.
+func Join(a, b Tree, fn func(idA, idB int) bool) {
+
+	sa, sb := sortedByFrom(a.GetAll()), sortedByFrom(b.GetAll())
+	activeA := make([]Interval, 0, 16)
+	activeB := make([]Interval, 0, 16)
+
+	var ok bool
+	i, j := 0, 0
+	for i < len(sa) || j < len(sb) {
+		if j == len(sb) || (i < len(sa) && sa[i].From <= sb[j].From) {
+			if j == len(sb) && len(activeB) == 0 {
+				return // The rest of a won't overlap with anything.
+			}
+			cur := sa[i]
+			i++
+			activeB, ok = sweep(activeB, cur, func(o Interval) bool {
+				return fn(cur.ID, o.ID)
+			})
+			activeA = append(activeA, cur)
+		} else {
+			if i == len(sa) && len(activeA) == 0 {
+				return
+			}
+			cur := sb[j]
+			j++
+			activeA, ok = sweep(activeA, cur, func(o Interval) bool {
+				return fn(o.ID, cur.ID)
+			})
+			activeB = append(activeB, cur)
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// sweep drops intervals which end before cur starts from active,
+// and passes the others to fn, all of them overlap with cur
+// because they start before (or with) cur.
+// It returns the remaining active intervals and false if fn stops the sweep.
+func sweep(active []Interval, cur Interval, fn func(o Interval) bool) ([]Interval, bool) {
+
+	n := 0
+	for _, o := range active {
+		if o.To < cur.From {
+			continue
+		}
+		if !fn(o) {
+			return active, false
+		}
+		active[n] = o
+		n++
+	}
+	return active[:n], true
+}
@@ -0,0 +1,115 @@
+package bsegtree
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestOverlappingPairs(t *testing.T) {
+
+	rand.Seed(time.Now().UnixNano())
+
+	tree := New()
+	from, to := make([]byte, 8), make([]byte, 8)
+	for i := 0; i < 512; i++ {
+		fn := rand.Int63n(100000)
+		tn := fn + rand.Int63n(1000)
+		binary.BigEndian.PutUint64(from, uint64(fn))
+		binary.BigEndian.PutUint64(to, uint64(tn))
+		tree.Push(from, to)
+	}
+
+	var act [][2]int
+	tree.(*BSTree).OverlappingPairs(func(a, b int) bool {
+		act = append(act, sortedPair(a, b))
+		return true
+	})
+
+	base := tree.GetAll()
+	var exp [][2]int
+	for i := range base {
+		for j := i + 1; j < len(base); j++ {
+			if !base[i].Disjoint(base[j].From, base[j].To) {
+				exp = append(exp, sortedPair(base[i].ID, base[j].ID))
+			}
+		}
+	}
+
+	cmpPairs(t, exp, act)
+
+	cnt := 0
+	tree.(*BSTree).OverlappingPairs(func(a, b int) bool {
+		cnt++
+		return false
+	})
+	if len(exp) != 0 && cnt != 1 {
+		t.Fatalf("pairs iteration should stop, got %d pairs", cnt)
+	}
+}
+
+func sortedPair(a, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
+
+func cmpPairs(t *testing.T, exp, act [][2]int) {
+
+	less := func(s [][2]int) func(i, j int) bool {
+		return func(i, j int) bool {
+			if s[i][0] != s[j][0] {
+				return s[i][0] < s[j][0]
+			}
+			return s[i][1] < s[j][1]
+		}
+	}
+	sort.Slice(exp, less(exp))
+	sort.Slice(act, less(act))
+
+	if len(exp) != len(act) {
+		t.Fatalf("wrong pairs count, exp: %d, got: %d", len(exp), len(act))
+	}
+	for i := range exp {
+		if exp[i] != act[i] {
+			t.Fatalf("wrong pair, exp: %v, got: %v", exp[i], act[i])
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+
+	rand.Seed(time.Now().UnixNano())
+
+	a, b := New(), NewSerial()
+	from, to := make([]byte, 8), make([]byte, 8)
+	for i := 0; i < 512; i++ {
+		for _, tree := range []Tree{a, b} {
+			fn := rand.Int63n(100000)
+			tn := fn + rand.Int63n(1000)
+			binary.BigEndian.PutUint64(from, uint64(fn))
+			binary.BigEndian.PutUint64(to, uint64(tn))
+			tree.Push(from, to)
+		}
+	}
+
+	var act [][2]int
+	Join(a, b, func(idA, idB int) bool {
+		act = append(act, [2]int{idA, idB})
+		return true
+	})
+
+	var exp [][2]int
+	for _, ia := range a.GetAll() {
+		for _, ib := range b.GetAll() {
+			if !ia.Disjoint(ib.From, ib.To) {
+				exp = append(exp, [2]int{ia.ID, ib.ID})
+			}
+		}
+	}
+
+	cmpPairs(t, exp, act)
+}
@@ -0,0 +1,63 @@
+package bsegtree
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestBuildParallel(t *testing.T) {
+
+	rand.Seed(time.Now().UnixNano())
+
+	for _, workers := range []int{0, 2, 3, 8, 64} {
+		seq, par := New(), New()
+		from, to := make([]byte, 8), make([]byte, 8)
+		for i := 0; i < 4096; i++ {
+			fn := rand.Int63n(1000000)
+			tn := fn + rand.Int63n(100000)
+			binary.BigEndian.PutUint64(from, uint64(fn))
+			binary.BigEndian.PutUint64(to, uint64(tn))
+			seq.Push(from, to)
+			par.Push(from, to)
+		}
+		seq.Build()
+		par.(*BSTree).BuildParallel(workers)
+
+		if !reflect.DeepEqual(seq, par) {
+			t.Fatalf("parallel build with %d workers mismatched", workers)
+		}
+	}
+}
+
+// BenchmarkBuildParallel compares Build with BuildParallel on 100k intervals,
+// GOMAXPROCS is set to workers.
+func BenchmarkBuildParallel(b *testing.B) {
+
+	tree := New()
+	from, to := make([]byte, 8), make([]byte, 8)
+	for i := 0; i < 100000; i++ {
+		fn := rand.Int63n(1 << 40)
+		binary.BigEndian.PutUint64(from, uint64(fn))
+		binary.BigEndian.PutUint64(to, uint64(fn+rand.Int63n(1<<32)))
+		tree.Push(from, to)
+	}
+
+	b.Run("Build", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tree.Build()
+		}
+	})
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers_%d", workers), func(b *testing.B) {
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(workers))
+			for i := 0; i < b.N; i++ {
+				tree.(*BSTree).BuildParallel(workers)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package bsegtree
+
+import "time"
+
+// Policy decides whether BSTree.Query scans the interval stack (serial)
+// or traverses the tree. Query scans if:
+// count of intervals <= ScanCount, or
+// estimated result count >= ScanResults && count of intervals <= ScanMaxCount.
+type Policy struct {
+	ScanCount    int
+	ScanResults  int
+	ScanMaxCount int
+
+	// Calibrate makes Build run Calibrate after building tree,
+	// which replaces the thresholds above.
+	Calibrate bool
+}
+
+// DefaultPolicy is tuned on an Intel i5-8500 with uniformly spread intervals.
+var DefaultPolicy = Policy{
+	ScanCount:    48,
+	ScanResults:  48,
+	ScanMaxCount: 1024,
+}
+
+// scan returns true if serial will be faster.
+func (p Policy) scan(count, cnt int) bool {
+	return (cnt >= p.ScanResults && count <= p.ScanMaxCount) || count <= p.ScanCount
+}
+
+// SetPolicy sets the policy of Query.
+func (t *BSTree) SetPolicy(p Policy) {
+	t.policy = p
+}
+
+// Policy returns the policy of Query in use.
+func (t *BSTree) Policy() Policy {
+	return t.policy
+}
+
+// calibrateQueries is the count of queries for each result size in Calibrate.
+const calibrateQueries = 16
+
+// calibrateRepeats is the count of runs of each query in Calibrate,
+// the fastest one is taken as its cost for cutting noise.
+const calibrateRepeats = 5
+
+// Calibrate measures serial & tree query on the built tree
+// with queries made from its intervals (from 1 result to all),
+// then sets (and returns) the policy picking the faster one on this hardware & data.
+// It takes about (log2(count) * count * 160) interval comparisons.
+//
+// Build the tree before using it, the policy in use is returned unchanged if it's not built.
+func (t *BSTree) Calibrate() Policy {
+
+	if len(t.nodes) == 0 {
+		return t.policy
+	}
+
+	p := Policy{
+		ScanCount:    0,
+		ScanResults:  t.count + 1,
+		ScanMaxCount: t.count,
+		Calibrate:    t.policy.Calibrate,
+	}
+
+	n := len(t.byFrom)
+	scanWins := true // Scan wins on all result sizes.
+	for k := 1; k <= n; k *= 2 {
+		var scanCost, treeCost time.Duration
+		cnt := 0
+		for j := 0; j < calibrateQueries; j++ {
+			// Queries starting at intervals spread over the key space, covering about k intervals.
+			i := j * (n - k) / calibrateQueries
+			from, to := t.byFrom[i].From, t.byFrom[i+k-1].From
+			c := t.estimateIntervals(from, to)
+			cnt += c
+
+			scanCost += minCost(func() { t.queryScan(from, to, c) })
+			treeCost += minCost(func() { t.queryTree(from, to, c) })
+		}
+		cnt /= calibrateQueries
+
+		if scanCost < treeCost {
+			if cnt < p.ScanResults {
+				p.ScanResults = cnt
+			}
+		} else {
+			// Scan must win on all bigger result sizes.
+			p.ScanResults = t.count + 1
+			scanWins = false
+		}
+	}
+	if scanWins {
+		p.ScanCount = t.count
+	}
+
+	t.policy = p
+	return p
+}
+
+// minCost returns the minimum duration of calibrateRepeats runs of f.
+func minCost(f func()) time.Duration {
+	var cost time.Duration
+	for i := 0; i < calibrateRepeats; i++ {
+		start := time.Now()
+		f()
+		if d := time.Since(start); i == 0 || d < cost {
+			cost = d
+		}
+	}
+	return cost
+}
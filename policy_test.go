@@ -0,0 +1,63 @@
+package bsegtree
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestCalibrate(t *testing.T) {
+
+	rand.Seed(time.Now().UnixNano())
+
+	tree, serial := New(), NewSerial()
+	tree.(*BSTree).SetPolicy(Policy{Calibrate: true})
+	from, to := make([]byte, 8), make([]byte, 8)
+	for i := 0; i < 2048; i++ {
+		fn := rand.Int63n(1000000)
+		tn := fn + rand.Int63n(10000)
+		binary.BigEndian.PutUint64(from, uint64(fn))
+		binary.BigEndian.PutUint64(to, uint64(tn))
+		tree.Push(from, to)
+		serial.Push(from, to)
+	}
+	tree.Build()
+
+	p := tree.(*BSTree).Policy()
+	if !p.Calibrate || p.ScanMaxCount != 2048 {
+		t.Fatalf("policy isn't calibrated by Build: %+v", p)
+	}
+
+	policies := []Policy{
+		p,
+		{ScanCount: 2048},   // Always scan.
+		{ScanResults: 2049}, // Always traverse tree.
+	}
+	for _, p := range policies {
+		tree.(*BSTree).SetPolicy(p)
+		for i := 0; i < 256; i++ {
+			fn := rand.Int63n(1000000)
+			tn := fn + rand.Int63n(100000)
+			binary.BigEndian.PutUint64(from, uint64(fn))
+			binary.BigEndian.PutUint64(to, uint64(tn))
+
+			cmpQueryWithSerial(t, tree, serial, from, to, 0, false, false)
+			cmpQueryWithSerial(t, tree, serial, from, nil, 0, false, true)
+		}
+	}
+}
+
+func TestCalibrateNotBuilt(t *testing.T) {
+
+	tree := New().(*BSTree)
+	tree.Push([]byte("1"), []byte("3"))
+	tree.Push([]byte("2"), []byte("4"))
+
+	if p := tree.Calibrate(); p != DefaultPolicy {
+		t.Fatalf("policy of tree not built is changed by Calibrate: %+v", p)
+	}
+	if p := tree.Policy(); p != DefaultPolicy {
+		t.Fatalf("policy of tree not built is changed by Calibrate: %+v", p)
+	}
+}
@@ -0,0 +1,31 @@
+package bsegtree
+
+// scanGeneric appends positions (plus off) of intervals overlapping [from, to] to dst,
+// intervals are in structure-of-arrays: froms[i] & tos[i] are From & To of the i-th one.
+func scanGeneric(froms, tos []uint64, from, to uint64, off int, dst []int) []int {
+
+	tos = tos[:len(froms)]
+	i := 0
+	// Unrolled by 4 without bounds checking in loop body, it's the scan on !amd64.
+	for ; i+4 <= len(froms); i += 4 {
+		f, e := froms[i:i+4:i+4], tos[i:i+4:i+4]
+		if f[0] <= to && e[0] >= from {
+			dst = append(dst, off+i)
+		}
+		if f[1] <= to && e[1] >= from {
+			dst = append(dst, off+i+1)
+		}
+		if f[2] <= to && e[2] >= from {
+			dst = append(dst, off+i+2)
+		}
+		if f[3] <= to && e[3] >= from {
+			dst = append(dst, off+i+3)
+		}
+	}
+	for ; i < len(froms); i++ {
+		if froms[i] <= to && tos[i] >= from {
+			dst = append(dst, off+i)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,64 @@
+package bsegtree
+
+import "math/bits"
+
+var useAVX2 = hasAVX2()
+
+// scan appends positions of intervals overlapping [from, to] to dst,
+// intervals are in structure-of-arrays: froms[i] & tos[i] are From & To of the i-th one.
+//
+// With AVX2, it compares 4 intervals in an instruction producing a match bitmask
+// for every 64 intervals, then collects the set bits.
+func scan(froms, tos []uint64, from, to uint64, dst []int) []int {
+
+	tos = tos[:len(froms)]
+	if !useAVX2 {
+		return scanGeneric(froms, tos, from, to, 0, dst)
+	}
+
+	n := len(froms) &^ 3
+	for base := 0; base < n; base += 64 {
+		end := base + 64
+		if end > n {
+			end = n
+		}
+		m := scanMaskAVX2(&froms[base], &tos[base], end-base, from, to)
+		m &= ^uint64(0) >> uint(64-(end-base))
+		for m != 0 {
+			dst = append(dst, base+bits.TrailingZeros64(m))
+			m &= m - 1
+		}
+	}
+	return scanGeneric(froms[n:], tos[n:], from, to, n, dst)
+}
+
+// scanMaskAVX2 returns the bitmask of intervals overlapping [from, to]
+// in froms[:n] & tos[:n], n must be a multiple of 4 and <= 64.
+// Bits >= n are garbage.
+//
+//go:noescape
+func scanMaskAVX2(froms, tos *uint64, n int, from, to uint64) uint64
+
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+func xgetbv() (eax, edx uint32)
+
+// hasAVX2 returns true if both of CPU & OS support AVX2.
+func hasAVX2() bool {
+
+	maxID, _, _, _ := cpuid(0, 0)
+	if maxID < 7 {
+		return false
+	}
+	_, _, ecx, _ := cpuid(1, 0)
+	const osxsave, avx = 1 << 27, 1 << 28
+	if ecx&osxsave == 0 || ecx&avx == 0 {
+		return false
+	}
+	if eax, _ := xgetbv(); eax&6 != 6 { // XMM & YMM states are saved by OS.
+		return false
+	}
+	_, ebx, _, _ := cpuid(7, 0)
+	const avx2 = 1 << 5
+	return ebx&avx2 != 0
+}
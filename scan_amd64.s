@@ -0,0 +1,60 @@
+#include "textflag.h"
+
+DATA bias<>+0(SB)/8, $0x8000000000000000
+GLOBL bias<>(SB), RODATA|NOPTR, $8
+
+// AVX2 only has signed compare, unsigned a > b is (a^bias) > (b^bias).
+
+// func scanMaskAVX2(froms, tos *uint64, n int, from, to uint64) uint64
+TEXT ·scanMaskAVX2(SB), NOSPLIT, $0-48
+	MOVQ froms+0(FP), SI
+	MOVQ tos+8(FP), DI
+	MOVQ n+16(FP), DX
+	VPBROADCASTQ bias<>(SB), Y0
+	VPBROADCASTQ from+24(FP), Y1
+	VPXOR        Y0, Y1, Y1
+	VPBROADCASTQ to+32(FP), Y2
+	VPXOR        Y0, Y2, Y2
+	XORQ         R8, R8 // Bitmask of disjoint intervals.
+	XORQ         CX, CX
+
+loop:
+	CMPQ      CX, DX
+	JGE       done
+	VMOVDQU   (SI)(CX*8), Y3
+	VPXOR     Y0, Y3, Y3
+	VPCMPGTQ  Y2, Y3, Y3 // From > to
+	VMOVDQU   (DI)(CX*8), Y4
+	VPXOR     Y0, Y4, Y4
+	VPCMPGTQ  Y4, Y1, Y4 // from > To
+	VPOR      Y3, Y4, Y3
+	VMOVMSKPD Y3, AX
+	SHLQ      CX, AX
+	ORQ       AX, R8
+	ADDQ      $4, CX
+	JMP       loop
+
+done:
+	VZEROUPPER
+	NOTQ R8
+	MOVQ R8, ret+40(FP)
+	RET
+
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+// func xgetbv() (eax, edx uint32)
+TEXT ·xgetbv(SB), NOSPLIT, $0-8
+	MOVL $0, CX
+	XGETBV
+	MOVL AX, eax+0(FP)
+	MOVL DX, edx+4(FP)
+	RET
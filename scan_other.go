@@ -0,0 +1,10 @@
+//go:build !amd64
+
+package bsegtree
+
+// scan appends positions of intervals overlapping [from, to] to dst,
+// intervals are in structure-of-arrays: froms[i] & tos[i] are From & To of the i-th one.
+// There is no NEON kernel, arm64 uses the unrolled generic scan too.
+func scan(froms, tos []uint64, from, to uint64, dst []int) []int {
+	return scanGeneric(froms, tos, from, to, 0, dst)
+}
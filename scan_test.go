@@ -0,0 +1,85 @@
+package bsegtree
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestScan(t *testing.T) {
+
+	rand.Seed(time.Now().UnixNano())
+
+	for n := 0; n < 300; n++ {
+		froms, tos := make([]uint64, n), make([]uint64, n)
+		for i := range froms {
+			// Spread over the whole uint64 space for checking unsigned comparison.
+			froms[i] = rand.Uint64() >> uint(rand.Intn(64))
+			tos[i] = froms[i] + rand.Uint64()>>uint(rand.Intn(64))
+			if tos[i] < froms[i] {
+				tos[i] = ^uint64(0)
+			}
+		}
+		from := rand.Uint64() >> uint(rand.Intn(64))
+		to := from + rand.Uint64()>>uint(rand.Intn(64))
+		if to < from {
+			to = ^uint64(0)
+		}
+
+		var exp []int
+		for i := range froms {
+			if !(Interval{From: froms[i], To: tos[i]}).Disjoint(from, to) {
+				exp = append(exp, i)
+			}
+		}
+		for _, act := range [][]int{
+			scan(froms, tos, from, to, nil),
+			scanGeneric(froms, tos, from, to, 0, nil),
+		} {
+			if len(act) != len(exp) {
+				t.Fatalf("wrong scan result length, exp: %d, got: %d", len(exp), len(act))
+			}
+			for i := range exp {
+				if exp[i] != act[i] {
+					t.Fatalf("wrong scan result, exp: %v, got: %v", exp, act)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+
+	const n = 1024
+	base := make([]Interval, n)
+	froms, tos := make([]uint64, n), make([]uint64, n)
+	for i := range base {
+		from := uint64(rand.Int63n(n * 16))
+		to := from + uint64(rand.Int63n(64))
+		base[i] = Interval{ID: i, From: from, To: to}
+		froms[i], tos[i] = from, to
+	}
+	from, to := uint64(n*8), uint64(n*8+64)
+	dst := make([]int, 0, n)
+
+	b.Run("kernel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			dst = scan(froms, tos, from, to, dst[:0])
+		}
+	})
+	b.Run("generic", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			dst = scanGeneric(froms, tos, from, to, 0, dst[:0])
+		}
+	})
+	b.Run("stack", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			dst = dst[:0]
+			for _, iv := range base {
+				if !iv.Disjoint(from, to) {
+					dst = append(dst, iv.ID)
+				}
+			}
+		}
+	})
+}
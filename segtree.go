@@ -0,0 +1,161 @@
+package bsegtree
+
+import (
+	"cmp"
+	"runtime"
+
+	"github.com/templexxx/bsegtree/internal/bitmap"
+)
+
+// segments is the built segment tree of keys K,
+// BSTree is the one of abbreviated keys (uint64).
+type segments[K cmp.Ordered] struct {
+	// Tree nodes, nodes[0] is the root.
+	nodes []node[K]
+	// Overlap interval IDs of all nodes.
+	pool []int32
+}
+
+// buildNodes creates tree nodes from interval endpoints,
+// and inserts intervals of base into them with workers goroutines.
+func (t *segments[K]) buildNodes(base []Span[K], endpoint []K, workers int) {
+
+	leaves := elementaryIntervals(endpoint)
+	t.nodes = make([]node[K], 0, len(leaves)*2-1)
+	t.insertNodes(leaves)
+	// Queries walk root-to-leaf paths, van Emde Boas layout keeps them in fewer cache lines.
+	t.nodes = vebLayout(t.nodes)
+	overlaps := make([][]int32, len(t.nodes))
+	if workers > 1 {
+		insertIntervalsParallel(t.nodes, base, overlaps, workers)
+	} else {
+		for i := range base {
+			insertInterval(t.nodes, 0, base[i], overlaps)
+		}
+	}
+	t.pool = packOverlaps(t.nodes, overlaps)
+}
+
+// insertNodes builds tree structure from given endpoints in preorder,
+// returns index of the subtree root.
+func (t *segments[K]) insertNodes(ls [][2]K) int32 {
+	n := int32(len(t.nodes))
+	t.nodes = append(t.nodes, node[K]{from: ls[0][0], to: ls[len(ls)-1][1]})
+	if len(ls) > 1 {
+		center := len(ls) / 2
+		left := t.insertNodes(ls[:center])
+		right := t.insertNodes(ls[center:])
+		t.nodes[n].left, t.nodes[n].right = left, right
+	}
+	return n
+}
+
+// querySingle traverse tree in search of overlaps
+func (t *segments[K]) querySingle(n int32, from, to K, result *[]int, bm *bitmap.Bitmap) {
+
+	nodes := t.nodes
+	node := &nodes[n]
+	if node.lo != node.hi {
+		for _, id := range t.pool[node.lo:node.hi] {
+			i := int(id)
+			if bm != nil {
+				if !bm.Get(i) {
+					*result = append(*result, i)
+					bm.Set(i, true)
+				}
+			} else {
+				*result = append(*result, i)
+			}
+		}
+	}
+	if node.left != 0 {
+		if right := node.right; !nodes[right].Disjoint(from, to) {
+			t.querySingle(right, from, to, result, bm)
+		}
+		if left := node.left; !nodes[left].Disjoint(from, to) {
+			t.querySingle(left, from, to, result, bm)
+		}
+	}
+}
+
+// SegTree is a segment tree of intervals of any ordered keys (integers, floats, strings),
+// keys are compared as they are, there is no abbreviation like BSTree.
+// It shares the building & querying algorithms with BSTree.
+//
+// Float keys must not be NaN.
+type SegTree[K cmp.Ordered] struct {
+	segments[K]
+	// interval stack
+	base []Span[K]
+}
+
+// NewSegTree creates a SegTree of keys K.
+func NewSegTree[K cmp.Ordered]() *SegTree[K] {
+	return new(SegTree[K])
+}
+
+// Push new interval [from, to] to stack, from & to are swapped if from > to.
+// This new interval will be added after Build.
+func (t *SegTree[K]) Push(from, to K) {
+	if from > to {
+		from, to = to, from
+	}
+	t.base = append(t.base, Span[K]{ID: len(t.base), From: from, To: to})
+}
+
+// Build builds segment tree out of interval stack
+func (t *SegTree[K]) Build() {
+	t.BuildParallel(1)
+}
+
+// BuildParallel builds segment tree like Build,
+// but inserts intervals into subtrees with workers goroutines.
+// workers <= 0 means runtime.GOMAXPROCS(0) like BSTree.BuildParallel.
+func (t *SegTree[K]) BuildParallel(workers int) {
+
+	if len(t.base) == 0 {
+		panic("No intervals in stack To build tree. Push intervals first")
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	endpoint, _, _ := Endpoints(t.base)
+	t.buildNodes(t.base, endpoint, workers)
+}
+
+// Query interval, return interval id.
+func (t *SegTree[K]) Query(from, to K) []int {
+
+	if len(t.nodes) == 0 {
+		return nil
+	}
+	if from > to {
+		from, to = to, from
+	}
+
+	var result []int
+	if !t.nodes[0].Disjoint(from, to) {
+		bm := bitmaps.Get(len(t.base))
+		t.querySingle(0, from, to, &result, bm)
+		bm.ClearBits(result)
+		bitmaps.Put(bm)
+	}
+	return result
+}
+
+// QueryPoint queries a point, return all intervals contains this point.
+func (t *SegTree[K]) QueryPoint(p K) []int {
+	return t.Query(p, p)
+}
+
+// Clear reset Tree.
+func (t *SegTree[K]) Clear() {
+	t.nodes = nil
+	t.pool = nil
+	t.base = t.base[:0]
+}
+
+// GetAll returns all intervals in stack.
+func (t *SegTree[K]) GetAll() []Span[K] {
+	return t.base
+}
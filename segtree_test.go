@@ -0,0 +1,86 @@
+package bsegtree
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// bruteForce returns IDs of intervals overlapping [from, to] (swapped if inverted).
+func bruteForce[K interface{ ~int | ~float64 | ~string }](base []Span[K], from, to K) []int {
+	if from > to {
+		from, to = to, from
+	}
+	var result []int
+	for _, iv := range base {
+		if !iv.Disjoint(from, to) {
+			result = append(result, iv.ID)
+		}
+	}
+	return result
+}
+
+func checkSegTree[K interface{ ~int | ~float64 | ~string }](t *testing.T, tree *SegTree[K], from, to K) {
+	t.Helper()
+
+	act := tree.Query(from, to)
+	sort.Ints(act)
+	exp := bruteForce(tree.GetAll(), from, to)
+	if len(act) != len(exp) || (len(exp) != 0 && !reflect.DeepEqual(act, exp)) {
+		t.Fatalf("mismatched result of [%v, %v], exp: %v, got: %v", from, to, exp, act)
+	}
+}
+
+func TestSegTree(t *testing.T) {
+
+	rand.Seed(time.Now().UnixNano())
+
+	ints := NewSegTree[int]()
+	floats := NewSegTree[float64]()
+	strs := NewSegTree[string]()
+	for i := 0; i < 512; i++ {
+		f, e := rand.Intn(2000)-1000, rand.Intn(2000)-1000 // May be inverted.
+		ints.Push(f, e)
+		floats.Push(float64(f)/7, float64(e)/7)
+		// Long strings with common prefix, which don't work in BSTree.
+		strs.Push("prefix-of-key-"+strconv.Itoa(f), "prefix-of-key-"+strconv.Itoa(e))
+	}
+	ints.Build()
+	floats.BuildParallel(4)
+	strs.BuildParallel(0)
+
+	for i := 0; i < 256; i++ {
+		f, e := rand.Intn(2200)-1100, rand.Intn(2200)-1100
+		checkSegTree(t, ints, f, e)
+		checkSegTree(t, ints, f, f)
+		checkSegTree(t, floats, float64(f)/7, float64(e)/7)
+		checkSegTree(t, strs, "prefix-of-key-"+strconv.Itoa(f), "prefix-of-key-"+strconv.Itoa(e))
+	}
+
+	ints.Clear()
+	if ints.Query(0, 1) != nil || len(ints.GetAll()) != 0 {
+		t.Fatal("tree isn't cleared")
+	}
+}
+
+// SegTree of uint64 must be the same tree as BSTree.
+func TestSegTreeSameAsBSTree(t *testing.T) {
+
+	rand.Seed(time.Now().UnixNano())
+
+	st, bt := NewSegTree[uint64](), New().(*BSTree)
+	for i := 0; i < 1024; i++ {
+		f := uint64(rand.Intn(100000))
+		e := f + uint64(rand.Intn(1000))
+		st.Push(f, e)
+		bt.PushUint64(f, e)
+	}
+	st.Build()
+	bt.Build()
+	if !reflect.DeepEqual(st.nodes, bt.nodes) || !reflect.DeepEqual(st.pool, bt.pool) {
+		t.Fatal("mismatched tree")
+	}
+}
@@ -8,7 +8,7 @@ package bsegtree
 // serial is a structure that allows to query intervals
 // with a sequential algorithm
 type serial struct {
-	BSTree
+	stack
 }
 
 // NewSerial returns a Tree interface with underlying serial algorithm
@@ -22,10 +22,24 @@ func (t *serial) Build() {
 	return
 }
 
+// Clear reset Tree.
+func (t *serial) Clear() {
+	t.stack.clear()
+}
+
+func (t *serial) Clone() Tree {
+	return &serial{stack: t.stack.clone()}
+}
+
 // Query interval by looping through the interval stack
 func (t *serial) Query(from, to []byte) []int {
+	return t.QueryUint64(AbbreviatedKey(from), AbbreviatedKey(to))
+}
+
+// QueryUint64 queries interval [fa, ta] of abbreviated keys, return interval id.
+func (t *serial) QueryUint64(fa, ta uint64) []int {
 
-	fa, ta := AbbreviatedKey(from), AbbreviatedKey(to)
+	fa, ta = orderedRange(fa, ta)
 
 	result := make([]int, 0, t.estimateIntervals(fa, ta))
 	for _, i := range t.base {
@@ -37,8 +51,11 @@ func (t *serial) Query(from, to []byte) []int {
 }
 
 func (t *serial) QueryPoint(p []byte) []int {
+	return t.QueryPointUint64(AbbreviatedKey(p))
+}
 
-	pa := AbbreviatedKey(p)
+// QueryPointUint64 queries a point of abbreviated key, return interval id.
+func (t *serial) QueryPointUint64(pa uint64) []int {
 
 	result := make([]int, 0, t.estimateIntervals(pa, pa))
 	for _, i := range t.base {
@@ -48,3 +65,13 @@ func (t *serial) QueryPoint(p []byte) []int {
 	}
 	return result
 }
+
+// QueryInt64 queries interval [from, to] of int64, return interval id.
+func (t *serial) QueryInt64(from, to int64) []int {
+	return t.QueryUint64(int64Key(from), int64Key(to))
+}
+
+// QueryPointInt64 queries a point of int64, return interval id.
+func (t *serial) QueryPointInt64(p int64) []int {
+	return t.QueryPointUint64(int64Key(p))
+}
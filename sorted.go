@@ -0,0 +1,128 @@
+package bsegtree
+
+import "sort"
+
+// sortedBlock is the count of intervals in a block of sortedSerial.
+const sortedBlock = 32
+
+// sortedSerial is serial on intervals sorted by From:
+// the binary search on From skips intervals start after query,
+// the binary search on prefix max To skips intervals end before query,
+// and the max To of every block skips blocks in the middle which end before query.
+//
+// It costs O(log(n) + k) for most queries, and its Build is only a sort.
+type sortedSerial struct {
+	stack
+	// Intervals sorted by From.
+	s []Interval
+	// prefixMaxTo[i] is the max To of s[:i+1], it's non-decreasing.
+	prefixMaxTo []uint64
+	// blockMaxTo[b] is the max To of s[b*sortedBlock:(b+1)*sortedBlock].
+	blockMaxTo []uint64
+}
+
+// NewSortedSerial returns a Tree interface with underlying serial algorithm on sorted intervals.
+func NewSortedSerial() Tree {
+	t := new(sortedSerial)
+	t.Clear()
+	return t
+}
+
+// Build sorts interval stack.
+func (t *sortedSerial) Build() {
+
+	if len(t.base) == 0 {
+		panic("No intervals in stack To build tree. Push intervals first")
+	}
+
+	t.s = sortedByFrom(t.base)
+	t.prefixMaxTo = make([]uint64, len(t.s))
+	t.blockMaxTo = make([]uint64, (len(t.s)+sortedBlock-1)/sortedBlock)
+	var m uint64
+	for i, iv := range t.s {
+		if iv.To > m {
+			m = iv.To
+		}
+		t.prefixMaxTo[i] = m
+		if b := i / sortedBlock; iv.To > t.blockMaxTo[b] {
+			t.blockMaxTo[b] = iv.To
+		}
+	}
+	t.min = t.s[0].From
+	t.max = m
+
+	t.byFrom = t.s
+	t.byTo = sortedByTo(t.base)
+	t.buildHistograms()
+}
+
+// Query interval, return interval id.
+func (t *sortedSerial) Query(from, to []byte) []int {
+	return t.QueryUint64(AbbreviatedKey(from), AbbreviatedKey(to))
+}
+
+// QueryUint64 queries interval [fa, ta] of abbreviated keys, return interval id.
+func (t *sortedSerial) QueryUint64(fa, ta uint64) []int {
+
+	if len(t.s) == 0 {
+		return nil
+	}
+
+	fa, ta = orderedRange(fa, ta)
+
+	start := sort.Search(len(t.s), func(i int) bool {
+		return t.prefixMaxTo[i] >= fa
+	})
+	end := sort.Search(len(t.s), func(i int) bool {
+		return t.s[i].From > ta
+	})
+
+	result := make([]int, 0, t.estimateIntervals(fa, ta))
+	for i := start; i < end; {
+		b := i / sortedBlock
+		next := (b + 1) * sortedBlock
+		if next > end {
+			next = end
+		}
+		if t.blockMaxTo[b] >= fa {
+			for _, iv := range t.s[i:next] {
+				if iv.To >= fa {
+					result = append(result, iv.ID)
+				}
+			}
+		}
+		i = next
+	}
+	return result
+}
+
+func (t *sortedSerial) QueryPoint(p []byte) []int {
+	return t.QueryPointUint64(AbbreviatedKey(p))
+}
+
+// QueryPointUint64 queries a point of abbreviated key, return interval id.
+func (t *sortedSerial) QueryPointUint64(p uint64) []int {
+	return t.QueryUint64(p, p)
+}
+
+// QueryInt64 queries interval [from, to] of int64, return interval id.
+func (t *sortedSerial) QueryInt64(from, to int64) []int {
+	return t.QueryUint64(int64Key(from), int64Key(to))
+}
+
+// QueryPointInt64 queries a point of int64, return interval id.
+func (t *sortedSerial) QueryPointInt64(p int64) []int {
+	return t.QueryPointUint64(int64Key(p))
+}
+
+// Clear reset Tree.
+func (t *sortedSerial) Clear() {
+	t.stack.clear()
+	t.s = nil
+	t.prefixMaxTo = nil
+	t.blockMaxTo = nil
+}
+
+func (t *sortedSerial) Clone() Tree {
+	return &sortedSerial{stack: t.stack.clone()}
+}
@@ -0,0 +1,30 @@
+package bsegtree
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+)
+
+func TestSortedSerialEqualSerial(t *testing.T) {
+	testEqualSerialSameLenInterval(t, NewSortedSerial)
+	testEqualSerialInterval(t, NewSortedSerial)
+}
+
+func BenchmarkQueryPartTreeSortedSerial(b *testing.B) {
+
+	ss := NewSortedSerial()
+	from, to := make([]byte, 8), make([]byte, 8)
+	for j := 0; j < 2048; j += 2 {
+		binary.BigEndian.PutUint64(from, uint64(j))
+		binary.BigEndian.PutUint64(to, uint64(j+1))
+		ss.Push(from, to)
+	}
+	ss.Build()
+
+	for i := 1; i <= 1024; i *= 4 {
+		b.Run(fmt.Sprintf("%d result", i), func(b *testing.B) {
+			benchmarkQueryPart(b, ss, i)
+		})
+	}
+}
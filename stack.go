@@ -0,0 +1,165 @@
+package bsegtree
+
+import "math"
+
+// stack is the interval stack shared by all trees,
+// with stats of endpoints for estimating result count.
+type stack struct {
+	count int // Number of intervals
+	// interval stack
+	base []Interval
+	// Min value of all intervals
+	min uint64
+	// Max value of all intervals
+	max uint64
+
+	// sum of To - from in intervals.
+	totalDeltas uint64
+
+	// (max - min) / totalDeltas
+	disjointPoint float64
+
+	// base sorted by From & To, made by Build.
+	byFrom []Interval
+	byTo   []Interval
+
+	// Equi-depth histograms of From & To, made by Build.
+	fromHist histogram
+	toHist   histogram
+}
+
+func (t *stack) GetAll() []Interval {
+	return t.base
+}
+
+// Push new interval [from, To] To stack
+// This new interval will be added after Build.
+func (t *stack) Push(from, to []byte) {
+	t.push(abbreviatedRange(from, to))
+}
+
+// PushUint64 pushes new interval [from, to] of abbreviated keys to stack.
+func (t *stack) PushUint64(from, to uint64) {
+	t.push(orderedRange(from, to))
+}
+
+// PushInt64 pushes new interval [from, to] of int64 to stack.
+func (t *stack) PushInt64(from, to int64) {
+	t.push(orderedRange(int64Key(from), int64Key(to)))
+}
+
+// push pushes interval [fa, ta] of abbreviated keys.
+func (t *stack) push(fa, ta uint64) {
+
+	t.base = append(t.base, Interval{t.count, fa, ta})
+	t.count++
+
+	if ta > t.max {
+		t.max = ta
+	}
+	if fa < t.min {
+		t.min = fa
+	}
+
+	t.totalDeltas += ta - fa
+
+	if t.totalDeltas != 0 && t.max-t.min != 0 {
+		t.disjointPoint = float64(t.max-t.min) / float64(t.totalDeltas)
+	}
+}
+
+// PushArray push new intervals [from, To] To stack.
+// These new intervals will be added after Build.
+func (t *stack) PushArray(from, to [][]byte) {
+	for i := 0; i < len(from); i++ {
+		t.Push(from[i], to[i])
+	}
+}
+
+// clear resets stack.
+func (t *stack) clear() {
+	t.count = 0
+	t.base = t.base[:0]
+
+	t.min = 0
+	t.max = 0
+
+	t.totalDeltas = 0
+	t.disjointPoint = 0
+
+	t.byFrom = nil
+	t.byTo = nil
+	t.fromHist = histogram{}
+	t.toHist = histogram{}
+}
+
+// clone returns a copy of interval stack without things made by Build.
+func (t *stack) clone() stack {
+
+	nt := stack{
+		count:         t.count,
+		base:          make([]Interval, 0, 1024),
+		min:           t.min,
+		max:           t.max,
+		totalDeltas:   t.totalDeltas,
+		disjointPoint: t.disjointPoint,
+	}
+
+	for _, i := range t.base {
+		nt.base = append(nt.base, Interval{
+			ID:   i.ID,
+			From: i.From,
+			To:   i.To,
+		})
+	}
+	return nt
+}
+
+// estimateIntervals estimates possible intervals count will be returned by Query/QueryPoint.
+// It uses histograms of endpoints made by Build,
+// intervals overlap [from, to] = intervals start <= to - intervals end < from.
+//
+// Without Build (e.g. serial), we assume the dealt of each interval is smooth. I hope so :D
+func (t *stack) estimateIntervals(from, to uint64) int {
+
+	if t.fromHist.n != 0 {
+		cnt := int(math.Round(t.fromHist.countLE(to) - t.toHist.countLT(from)))
+		if cnt < 1 {
+			return 1
+		}
+		if cnt > t.count {
+			return t.count
+		}
+		return cnt
+	}
+
+	if t.max == t.min {
+		return 1
+	}
+
+	delta := float64(to - from)
+
+	var cnt int
+	if delta == 0 && t.disjointPoint != 0 {
+
+		cnt = int(round(1/t.disjointPoint, 0))
+
+	} else {
+		cnt = int((delta*float64(t.count))/float64(t.max-t.min)) + 1 // +1 for potential cross intervals and point query.
+	}
+
+	if cnt < 1 {
+		return 1
+	}
+	if cnt > t.count {
+		return t.count
+	}
+	return cnt
+
+}
+
+// Estimate estimates intervals count will be returned by Query(from, to),
+// it's at least 1.
+func (t *stack) Estimate(from, to []byte) int {
+	return t.estimateIntervals(abbreviatedRange(from, to))
+}
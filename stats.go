@@ -0,0 +1,69 @@
+package bsegtree
+
+import "unsafe"
+
+// Stats is structural statistics of BSTree.
+type Stats struct {
+	Intervals int // Count of intervals in stack.
+	Nodes     int // Count of tree nodes, 0 if the tree isn't built.
+	Depth     int // Levels of tree, root is level 1.
+	Leaves    int // Count of elementary intervals.
+
+	// Overlaps is the total length of overlap lists,
+	// MaxOverlaps is the length of the longest one.
+	Overlaps    int
+	MaxOverlaps int
+	// Duplication is the average count of nodes storing an interval,
+	// it's about log2(Leaves) at most.
+	Duplication float64
+
+	// Memory is the estimated bytes used by tree, interval stack & sorted copies of it.
+	Memory int
+
+	// Values used by estimating result count.
+	Min           uint64
+	Max           uint64
+	DisjointPoint float64
+}
+
+// Stats returns statistics of tree.
+func (t *BSTree) Stats() Stats {
+
+	s := Stats{
+		Intervals:     t.count,
+		Nodes:         len(t.nodes),
+		Overlaps:      len(t.pool),
+		Min:           t.min,
+		Max:           t.max,
+		DisjointPoint: t.disjointPoint,
+	}
+
+	if len(t.nodes) != 0 {
+		s.Depth = height(t.nodes, 0)
+	}
+	for i := range t.nodes {
+		n := &t.nodes[i]
+		if n.left == 0 {
+			s.Leaves++
+		}
+		if l := int(n.hi - n.lo); l > s.MaxOverlaps {
+			s.MaxOverlaps = l
+		}
+	}
+	if t.count != 0 {
+		s.Duplication = float64(s.Overlaps) / float64(t.count)
+	}
+
+	ivSize := int(unsafe.Sizeof(Interval{}))
+	s.Memory = cap(t.nodes)*int(unsafe.Sizeof(node[uint64]{})) +
+		cap(t.pool)*4 +
+		cap(t.base)*ivSize +
+		(cap(t.froms)+cap(t.tos))*8 +
+		(len(t.fromHist.bounds)+len(t.toHist.bounds))*16 // bounds & pos.
+	s.Memory += cap(t.byFrom) * ivSize
+	// byTo is byFrom itself after BuildSorted.
+	if len(t.byTo) != 0 && (len(t.byFrom) == 0 || &t.byTo[0] != &t.byFrom[0]) {
+		s.Memory += cap(t.byTo) * ivSize
+	}
+	return s
+}
@@ -0,0 +1,40 @@
+package bsegtree
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+
+	tree := New().(*BSTree)
+	if s := tree.Stats(); s.Nodes != 0 || s.Depth != 0 || s.Memory != 0 {
+		t.Fatalf("wrong stats of empty tree: %+v", s)
+	}
+
+	from, to := make([]byte, 8), make([]byte, 8)
+	for _, iv := range [][2]uint64{{1, 10}, {2, 3}, {5, 20}, {5, 20}} {
+		binary.BigEndian.PutUint64(from, iv[0])
+		binary.BigEndian.PutUint64(to, iv[1])
+		tree.Push(from, to)
+	}
+	tree.Build()
+
+	s := tree.Stats()
+	// Endpoints: 1, 2, 3, 5, 10, 20.
+	if s.Intervals != 4 || s.Leaves != 11 || s.Nodes != 21 || s.Depth != 5 {
+		t.Fatalf("wrong structure stats: %+v", s)
+	}
+	if s.Min != 1 || s.Max != 20 {
+		t.Fatalf("wrong min/max: %+v", s)
+	}
+	if s.Overlaps != len(tree.pool) || s.MaxOverlaps < 2 {
+		t.Fatalf("wrong overlap stats: %+v", s)
+	}
+	if s.Duplication < 1 || s.Duplication != float64(s.Overlaps)/4 {
+		t.Fatalf("wrong duplication: %+v", s)
+	}
+	if s.Memory <= 0 {
+		t.Fatalf("wrong memory: %+v", s)
+	}
+}
@@ -0,0 +1,108 @@
+package bsegtree
+
+import (
+	"container/heap"
+	"sort"
+
+	"github.com/templexxx/bsegtree/internal/bitmap"
+)
+
+// QueryTopK queries interval, returns ids of at most k overlapping intervals
+// which are the smallest by less, in ascending order.
+//
+// e.g. less for the smallest intervals:
+// func(a, b Interval) bool { return a.To-a.From < b.To-b.From }
+//
+// It keeps only the best k intervals in a heap instead of collecting & sorting all results of Query,
+// but the traversal still visits every overlapping interval like Query.
+func (t *BSTree) QueryTopK(from, to []byte, k int, less func(a, b Interval) bool) []int {
+
+	if len(t.nodes) == 0 || k <= 0 {
+		return nil
+	}
+
+	fa, ta := abbreviatedRange(from, to)
+
+	size := k // There won't be more than count results.
+	if size > t.count {
+		size = t.count
+	}
+	h := &topK{k: k, less: less, s: make([]Interval, 0, size)}
+	bm := bitmaps.Get(t.count)
+	seen := make([]int, 0, t.estimateIntervals(fa, ta))
+	t.queryTopK(0, fa, ta, h, bm, &seen)
+	bm.ClearBits(seen)
+	bitmaps.Put(bm)
+
+	sort.Sort(sort.Reverse(h))
+	result := make([]int, len(h.s))
+	for i, iv := range h.s {
+		result[i] = iv.ID
+	}
+	return result
+}
+
+// queryTopK traverse tree in search of overlaps, keeping the top k in h,
+// IDs set in bm are appended to seen for clearing it.
+func (t *BSTree) queryTopK(n int32, from, to uint64, h *topK, bm *bitmap.Bitmap, seen *[]int) {
+
+	node := &t.nodes[n]
+	if !node.Disjoint(from, to) {
+
+		for _, id := range t.pool[node.lo:node.hi] {
+			i := int(id)
+			if !bm.Get(i) {
+				bm.Set(i, true)
+				*seen = append(*seen, i)
+				h.offer(t.base[i])
+			}
+		}
+		if node.left != 0 {
+			t.queryTopK(node.right, from, to, h, bm, seen)
+			t.queryTopK(node.left, from, to, h, bm, seen)
+		}
+	}
+}
+
+// topK is a max-heap (by less) holding at most k intervals,
+// the root is the worst one, which will be replaced by a better one.
+type topK struct {
+	k    int
+	less func(a, b Interval) bool
+	s    []Interval
+}
+
+func (h *topK) Len() int {
+	return len(h.s)
+}
+
+func (h *topK) Less(i, j int) bool {
+	return h.less(h.s[j], h.s[i])
+}
+
+func (h *topK) Swap(i, j int) {
+	h.s[i], h.s[j] = h.s[j], h.s[i]
+}
+
+func (h *topK) Push(x interface{}) {
+	h.s = append(h.s, x.(Interval))
+}
+
+func (h *topK) Pop() interface{} {
+	n := len(h.s) - 1
+	x := h.s[n]
+	h.s = h.s[:n]
+	return x
+}
+
+// offer adds i into heap if it's better than the worst one.
+func (h *topK) offer(i Interval) {
+	if len(h.s) < h.k {
+		heap.Push(h, i)
+		return
+	}
+	if h.less(i, h.s[0]) {
+		h.s[0] = i
+		heap.Fix(h, 0)
+	}
+}
@@ -0,0 +1,63 @@
+package bsegtree
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestQueryTopK(t *testing.T) {
+
+	rand.Seed(time.Now().UnixNano())
+
+	tree := New()
+	from, to := make([]byte, 8), make([]byte, 8)
+	for i := 0; i < 1024; i++ {
+		fn := rand.Int63n(100000)
+		tn := fn + rand.Int63n(10000)
+		binary.BigEndian.PutUint64(from, uint64(fn))
+		binary.BigEndian.PutUint64(to, uint64(tn))
+		tree.Push(from, to)
+	}
+	tree.Build()
+	bt := tree.(*BSTree)
+
+	// Smallest size first, ties by the newest.
+	less := func(a, b Interval) bool {
+		if a.To-a.From != b.To-b.From {
+			return a.To-a.From < b.To-b.From
+		}
+		return a.ID > b.ID
+	}
+
+	for i := 0; i < 256; i++ {
+		fn := rand.Int63n(110000)
+		tn := fn + rand.Int63n(20000)
+		binary.BigEndian.PutUint64(from, uint64(fn))
+		binary.BigEndian.PutUint64(to, uint64(tn))
+
+		all := tree.Query(from, to)
+		sort.Slice(all, func(i, j int) bool {
+			return less(tree.GetAll()[all[i]], tree.GetAll()[all[j]])
+		})
+
+		for _, k := range []int{0, 1, 8, 2048, math.MaxInt} {
+			exp := all
+			if len(exp) > k {
+				exp = exp[:k]
+			}
+			act := bt.QueryTopK(from, to, k, less)
+			if len(act) != len(exp) {
+				t.Fatalf("wrong top %d length, exp: %d, got: %d", k, len(exp), len(act))
+			}
+			for j := range exp {
+				if exp[j] != act[j] {
+					t.Fatalf("wrong top %d, exp: %v, got: %v", k, exp, act)
+				}
+			}
+		}
+	}
+}
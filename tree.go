@@ -9,6 +9,7 @@ import "encoding/binary"
 type Tree interface {
 	// Push new interval [from, to] to stack
 	// This new interval will be added after Build.
+	// from & to are swapped if from > to, for Query too.
 	Push(from, to []byte)
 	// PushArray push new intervals [from, to] to stack.
 	// These new intervals will be added after Build.
@@ -29,6 +30,33 @@ type Tree interface {
 	GetAll() []Interval
 }
 
+// NumericTree is a Tree with numeric keys, all trees in this package implement it,
+// get it by type assertion: New().(NumericTree).
+type NumericTree interface {
+	Tree
+
+	// PushUint64, QueryUint64 & QueryPointUint64 are Push, Query & QueryPoint
+	// with keys which are abbreviated keys already (e.g. timestamps, offsets),
+	// there is no bytes conversion.
+	PushUint64(from, to uint64)
+	QueryUint64(from, to uint64) []int
+	QueryPointUint64(p uint64) []int
+	// PushInt64, QueryInt64 & QueryPointInt64 are the signed version of the ones above,
+	// int64 is mapped to uint64 in the same order by flipping sign bit,
+	// which is the abbreviated key of big endian encoded int64 with sign bit flipped.
+	PushInt64(from, to int64)
+	QueryInt64(from, to int64) []int
+	QueryPointInt64(p int64) []int
+}
+
+var (
+	_ NumericTree = (*BSTree)(nil)
+	_ NumericTree = (*serial)(nil)
+	_ NumericTree = (*itree)(nil)
+	_ NumericTree = (*nclist)(nil)
+	_ NumericTree = (*sortedSerial)(nil)
+)
+
 // AbbreviatedKey returns a fixed length prefix of a user key such that AbbreviatedKey(a)
 // < AbbreviatedKey(b) iff a < b and AbbreviatedKey(a) > AbbreviatedKey(b) iff a > b. If
 // AbbreviatedKey(a) == AbbreviatedKey(b) an additional comparison is required to
@@ -50,3 +78,22 @@ func AbbreviatedKey(key []byte) uint64 {
 	}
 	return v << uint(8*(8-len(key)))
 }
+
+// abbreviatedRange returns abbreviated keys of range [from, to],
+// they're swapped if from > to, so an inverted range means the same as the normal one.
+func abbreviatedRange(from, to []byte) (uint64, uint64) {
+	return orderedRange(AbbreviatedKey(from), AbbreviatedKey(to))
+}
+
+// orderedRange returns range [from, to], they're swapped if from > to.
+func orderedRange(from, to uint64) (uint64, uint64) {
+	if from > to {
+		return to, from
+	}
+	return from, to
+}
+
+// int64Key maps v to uint64 in the same order by flipping sign bit.
+func int64Key(v int64) uint64 {
+	return uint64(v) ^ 1<<63
+}
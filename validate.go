@@ -0,0 +1,142 @@
+package bsegtree
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrNotBuilt is returned by Validate when there are intervals but no tree.
+var ErrNotBuilt = errors.New("bsegtree: tree is not built")
+
+// Validate checks structural invariants of built tree:
+//
+// 1. Each inner node is [from, to] of its children, children are in order
+// 2. Leaves (from left to right) are the elementary intervals of the interval stack
+// 3. Each interval is stored canonically: in nodes it's a superset of, but not of their parents,
+// and these nodes cover all leaves it's a superset of (so it can't be stored in a node and its ancestor)
+// 4. Every ID in overlap lists is an interval in stack
+//
+// It's useful after loading a tree from outside. It returns nil for an empty tree.
+func (t *BSTree) Validate() error {
+
+	if len(t.nodes) == 0 {
+		if len(t.base) == 0 {
+			return nil
+		}
+		return ErrNotBuilt
+	}
+	if len(t.base) == 0 {
+		return fmt.Errorf("bsegtree: %d nodes, but no intervals in stack", len(t.nodes))
+	}
+	if t.count != len(t.base) {
+		return fmt.Errorf("bsegtree: count %d mismatches %d intervals in stack", t.count, len(t.base))
+	}
+	for i, iv := range t.base {
+		if iv.ID != i {
+			return fmt.Errorf("bsegtree: interval at %d has ID %d", i, iv.ID)
+		}
+		if iv.From > iv.To {
+			return fmt.Errorf("bsegtree: interval %d is inverted", i)
+		}
+	}
+
+	nodes := t.nodes
+	parents := make([]int32, len(nodes))
+	leafCnt := make([]int, len(nodes)) // Count of leaves in subtree.
+	seen := make([]bool, len(nodes))
+	var leaves [][2]uint64
+
+	var walk func(n int32) error
+	walk = func(n int32) error {
+		if n < 0 || int(n) >= len(nodes) {
+			return fmt.Errorf("bsegtree: node index %d out of range", n)
+		}
+		if seen[n] {
+			return fmt.Errorf("bsegtree: node %d is reached twice", n)
+		}
+		seen[n] = true
+		nd := &nodes[n]
+		if nd.from > nd.to {
+			return fmt.Errorf("bsegtree: node %d [%d, %d] is inverted", n, nd.from, nd.to)
+		}
+		if nd.left == 0 {
+			if nd.right != 0 {
+				return fmt.Errorf("bsegtree: node %d has only right child", n)
+			}
+			leaves = append(leaves, [2]uint64{nd.from, nd.to})
+			leafCnt[n] = 1
+			return nil
+		}
+		for _, c := range []int32{nd.left, nd.right} {
+			if c <= 0 || int(c) >= len(nodes) {
+				return fmt.Errorf("bsegtree: child index %d of node %d out of range", c, n)
+			}
+			parents[c] = n
+			if err := walk(c); err != nil {
+				return err
+			}
+			leafCnt[n] += leafCnt[c]
+		}
+		l, r := &nodes[nd.left], &nodes[nd.right]
+		if l.from != nd.from || r.to != nd.to || l.to > r.from {
+			return fmt.Errorf("bsegtree: children [%d, %d] & [%d, %d] aren't nested in node %d [%d, %d]",
+				l.from, l.to, r.from, r.to, n, nd.from, nd.to)
+		}
+		return nil
+	}
+	parents[0] = -1
+	if err := walk(0); err != nil {
+		return err
+	}
+	for n := range seen {
+		if !seen[n] {
+			return fmt.Errorf("bsegtree: node %d isn't in tree", n)
+		}
+	}
+
+	endpoint, _, _ := Endpoints(t.base)
+	exp := elementaryIntervals(endpoint)
+	if len(exp) != len(leaves) {
+		return fmt.Errorf("bsegtree: %d leaves, but %d elementary intervals", len(leaves), len(exp))
+	}
+	for i := range exp {
+		if exp[i] != leaves[i] {
+			return fmt.Errorf("bsegtree: leaf %d is %v, but elementary interval is %v", i, leaves[i], exp[i])
+		}
+	}
+
+	covered := make([]int, len(t.base)) // Count of leaves covered by nodes storing the interval.
+	for n := range nodes {
+		nd := &nodes[n]
+		if nd.lo > nd.hi || nd.lo < 0 || int(nd.hi) > len(t.pool) {
+			return fmt.Errorf("bsegtree: overlap list [%d, %d) of node %d out of pool", nd.lo, nd.hi, n)
+		}
+		for _, id := range t.pool[nd.lo:nd.hi] {
+			if id < 0 || int(id) >= len(t.base) {
+				return fmt.Errorf("bsegtree: node %d stores unknown interval %d", n, id)
+			}
+			iv := t.base[id]
+			if nd.CompareTo(iv) != SUBSET {
+				return fmt.Errorf("bsegtree: node %d [%d, %d] isn't a subset of interval %d", n, nd.from, nd.to, id)
+			}
+			if p := parents[n]; p >= 0 && nodes[p].CompareTo(iv) == SUBSET {
+				return fmt.Errorf("bsegtree: interval %d is stored in node %d, but its parent %d is also covered (not canonical)", id, n, p)
+			}
+			covered[id] += leafCnt[n]
+		}
+	}
+	for i, iv := range t.base {
+		lo := sort.Search(len(leaves), func(j int) bool {
+			return leaves[j][0] >= iv.From
+		})
+		hi := sort.Search(len(leaves), func(j int) bool {
+			return leaves[j][1] > iv.To
+		})
+		if covered[i] != hi-lo {
+			return fmt.Errorf("bsegtree: interval %d is stored in nodes covering %d leaves, expected %d",
+				i, covered[i], hi-lo)
+		}
+	}
+	return nil
+}
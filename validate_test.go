@@ -0,0 +1,71 @@
+package bsegtree
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+
+	rand.Seed(time.Now().UnixNano())
+
+	tree := New().(*BSTree)
+	if err := tree.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	from, to := make([]byte, 8), make([]byte, 8)
+	for i := 0; i < 256; i++ {
+		f := rand.Uint64() % 1000
+		binary.BigEndian.PutUint64(from, f)
+		binary.BigEndian.PutUint64(to, f+rand.Uint64()%100)
+		tree.Push(from, to)
+	}
+	if err := tree.Validate(); err != ErrNotBuilt {
+		t.Fatalf("should be not built, got: %v", err)
+	}
+	tree.Build()
+	if err := tree.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	corrupt := func(name string, f func(c *BSTree)) {
+		c := *tree
+		c.nodes = append([]node[uint64](nil), tree.nodes...)
+		c.pool = append([]int32(nil), tree.pool...)
+		f(&c)
+		if c.Validate() == nil {
+			t.Fatalf("corrupted tree passes validation: %s", name)
+		}
+	}
+	corrupt("unknown ID", func(c *BSTree) {
+		c.pool[0] = int32(len(c.base))
+	})
+	corrupt("dropped ID", func(c *BSTree) {
+		for i := range c.nodes {
+			if c.nodes[i].hi > c.nodes[i].lo {
+				c.nodes[i].hi--
+				return
+			}
+		}
+	})
+	corrupt("not nested", func(c *BSTree) {
+		c.nodes[c.nodes[0].left].from--
+	})
+	corrupt("stored in ancestor", func(c *BSTree) {
+		// Store an interval of a node in the parent too.
+		for i := range c.nodes {
+			n := &c.nodes[i]
+			if n.left != 0 && c.nodes[n.left].hi > c.nodes[n.left].lo {
+				c.pool = append(c.pool, c.pool[c.nodes[n.left].lo])
+				n.lo, n.hi = int32(len(c.pool)-1), int32(len(c.pool))
+				return
+			}
+		}
+	})
+	corrupt("lost leaf", func(c *BSTree) {
+		n := &c.nodes[0]
+		n.right = n.left
+	})
+}